@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gotest.tools/gotestsum/internal/coverprofile"
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// coverProfileArg returns the value of the -coverprofile flag in args, or an
+// empty string if the flag is not set.
+func coverProfileArg(args []string) string {
+	start, end := argIndex("coverprofile", args)
+	switch {
+	case start < 0:
+		return ""
+	case start == end:
+		return args[start][strings.Index(args[start], "=")+1:]
+	case end < len(args):
+		return args[end]
+	default:
+		return ""
+	}
+}
+
+// coverProfileFile returns the coverage profile written by 'go test'. Either
+// the value of the -coverprofile flag, or the temporary file used when a
+// coverage report is requested without the -coverprofile flag.
+func coverProfileFile(opts *options) string {
+	if filename := coverProfileArg(opts.args); filename != "" {
+		return filename
+	}
+	return opts.coverProfile
+}
+
+// withCoverProfile returns a copy of opts with the value of the -coverprofile
+// flag replaced by filename. Used so that each 'go test' process started for
+// a shard or re-run writes to a different coverage profile.
+func withCoverProfile(opts *options, filename string) *options {
+	start, end := argIndex("coverprofile", opts.args)
+	if start < 0 {
+		if opts.coverProfile == "" {
+			return opts
+		}
+		result := *opts
+		result.coverProfile = filename
+		return &result
+	}
+	if start != end && end >= len(opts.args) {
+		return opts
+	}
+	args := append([]string{}, opts.args...)
+	if start == end {
+		args[start] = args[start][:strings.Index(args[start], "=")+1] + filename
+	} else {
+		args[end] = filename
+	}
+	result := *opts
+	result.args = args
+	return &result
+}
+
+// mergeCoverProfiles merges the coverage profiles in sources into the profile
+// in target, and removes the sources. Sources which do not exist, because the
+// 'go test' process failed before writing the profile, are ignored.
+func mergeCoverProfiles(target string, sources []string) error {
+	merged, err := readCoverProfile(target)
+	if err != nil {
+		return err
+	}
+	return writeMergedCoverProfile(target, merged, sources)
+}
+
+// replaceCoverProfile writes the merged coverage profiles in sources to target,
+// and removes the sources. Unlike mergeCoverProfiles, any existing profile in
+// target, from an earlier run, is replaced.
+func replaceCoverProfile(target string, sources []string) error {
+	return writeMergedCoverProfile(target, coverprofile.New(), sources)
+}
+
+func writeMergedCoverProfile(target string, merged *coverprofile.Profile, sources []string) error {
+	for _, source := range sources {
+		profile, err := readCoverProfile(source)
+		if err != nil {
+			return err
+		}
+		if err := merged.Merge(profile); err != nil {
+			return fmt.Errorf("failed to merge coverage profile %v: %v", source, err)
+		}
+	}
+
+	f, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("failed to open coverage profile: %v", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Errorf("Failed to close coverage profile: %v", err)
+		}
+	}()
+	if err := merged.Write(f); err != nil {
+		return fmt.Errorf("failed to write coverage profile: %v", err)
+	}
+
+	for _, source := range sources {
+		if err := os.Remove(source); err != nil && !os.IsNotExist(err) {
+			log.Warnf("Failed to remove coverage profile %v: %v", source, err)
+		}
+	}
+	return nil
+}
+
+// readCoverProfile reads the coverage profile from filename. Returns an empty
+// profile if the file does not exist.
+func readCoverProfile(filename string) (*coverprofile.Profile, error) {
+	f, err := os.Open(filename)
+	switch {
+	case os.IsNotExist(err):
+		return coverprofile.New(), nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read coverage profile: %v", err)
+	}
+	defer f.Close() // nolint: errcheck
+
+	profile, err := coverprofile.Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse coverage profile %v: %v", filename, err)
+	}
+	return profile, nil
+}
+
+// setTotalCoverage sets the total coverage of exec from the coverage profile
+// written by 'go test', if the -coverprofile flag was used.
+func setTotalCoverage(opts *options, exec *testjson.Execution) error {
+	filename := coverProfileFile(opts)
+	if filename == "" {
+		return nil
+	}
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		log.Debugf("coverage profile %v does not exist", filename)
+		return nil
+	}
+	profile, err := readCoverProfile(filename)
+	if err != nil {
+		return err
+	}
+	exec.SetTotalCoverage(profile.Percent())
+	return nil
+}
+
+// newCoverProfileFile returns the name of a temporary file for the coverage
+// profile, when a coverage report is requested and the -coverprofile flag is
+// not set. The returned function removes the file.
+func newCoverProfileFile(opts *options) (string, func(), error) {
+	if opts.coberturaFile == "" && opts.lcovFile == "" {
+		return "", func() {}, nil
+	}
+	if opts.rawCommand || coverProfileArg(opts.args) != "" {
+		return "", func() {}, nil
+	}
+	f, err := ioutil.TempFile("", "gotestsum-coverprofile-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create coverage profile: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", nil, fmt.Errorf("failed to create coverage profile: %v", err)
+	}
+	remove := func() {
+		if err := os.Remove(f.Name()); err != nil && !os.IsNotExist(err) {
+			log.Warnf("Failed to remove coverage profile %v: %v", f.Name(), err)
+		}
+	}
+	return f.Name(), remove, nil
+}
+
+// writeCoverageReports writes the --cobertura and --lcov reports from the
+// coverage profile written by 'go test'.
+func writeCoverageReports(opts *options) error {
+	if opts.coberturaFile == "" && opts.lcovFile == "" {
+		return nil
+	}
+	filename := coverProfileFile(opts)
+	if _, err := os.Stat(filename); filename == "" || os.IsNotExist(err) {
+		log.Warnf("Coverage profile %v does not exist, coverage reports were not written", filename)
+		return nil
+	}
+	profile, err := readCoverProfile(filename)
+	if err != nil {
+		return err
+	}
+
+	if opts.coberturaFile != "" {
+		err := writeCoverageReport(opts.coberturaFile, func(out io.Writer) error {
+			return coverprofile.WriteCobertura(out, profile, time.Now())
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if opts.lcovFile != "" {
+		err := writeCoverageReport(opts.lcovFile, func(out io.Writer) error {
+			return coverprofile.WriteLCOV(out, profile)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCoverageReport(filename string, write func(out io.Writer) error) error {
+	fh, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open coverage report file: %v", err)
+	}
+	defer func() {
+		if err := fh.Close(); err != nil {
+			log.Errorf("Failed to close coverage report file: %v", err)
+		}
+	}()
+	return write(fh)
+}
+
+// coverageMinimums is a list of patterns that match the name of a package, and
+// the minimum coverage for the packages which match.
+type coverageMinimums []coverageMinimum
+
+type coverageMinimum struct {
+	pattern string
+	percent float64
+}
+
+// readCoverageMinFile reads a list of minimums from a file. Each line of the
+// file is a package pattern followed by the minimum coverage percentage. Blank
+// lines, and lines which start with # are ignored.
+func readCoverageMinFile(filename string) (coverageMinimums, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage minimum file: %v", err)
+	}
+	defer fh.Close() // nolint: errcheck
+
+	var result coverageMinimums
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid coverage minimum %q: expected a package and a percentage", line)
+		}
+		if _, err := path.Match(fields[0], ""); err != nil {
+			return nil, fmt.Errorf("invalid coverage minimum pattern %q: %v", fields[0], err)
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverage minimum percentage %q: %v", fields[1], err)
+		}
+		result = append(result, coverageMinimum{pattern: fields[0], percent: percent})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read coverage minimum file: %v", err)
+	}
+	return result, nil
+}
+
+// forPackage returns the minimum coverage for the package. The last pattern
+// which matches the full package path, or the package path relative to the
+// module root, is used. Returns defaultMin if no pattern matches.
+func (m coverageMinimums) forPackage(pkg string, defaultMin float64) float64 {
+	result := defaultMin
+	for _, minimum := range m {
+		for _, name := range []string{pkg, testjson.RelativePackagePath(pkg)} {
+			if ok, _ := path.Match(minimum.pattern, name); ok {
+				result = minimum.percent
+				break
+			}
+		}
+	}
+	return result
+}
+
+// coverageMinErr returns an error if the coverage of any package, or the total
+// coverage, is below the minimum set by --coverage-min or --coverage-min-file.
+// A package with a minimum, but no coverage data, is also an error, because
+// the minimum can not be checked without the -cover flag.
+func coverageMinErr(opts *options, exec *testjson.Execution) error {
+	if opts.coverageMin <= 0 && len(opts.coverageMinimums) == 0 {
+		return nil
+	}
+
+	var lines []string
+	for _, name := range exec.Packages() {
+		minimum := opts.coverageMinimums.forPackage(name, opts.coverageMin)
+		if minimum <= 0 {
+			continue
+		}
+		percent, ok := exec.Package(name).CoveragePercent()
+		switch {
+		case !ok:
+			lines = append(lines, fmt.Sprintf("  %s has no coverage data", name))
+		case percent < minimum:
+			lines = append(lines, fmt.Sprintf("  %s %.1f%% < %.1f%%", name, percent, minimum))
+		}
+	}
+	if total, ok := exec.TotalCoverage(); ok && total < opts.coverageMin {
+		lines = append(lines, fmt.Sprintf("  total %.1f%% < %.1f%%", total, opts.coverageMin))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return fmt.Errorf("coverage is below the minimum:\n%s", strings.Join(lines, "\n"))
+}
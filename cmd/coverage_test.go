@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestCoverProfileArg(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{args: []string{"-v"}, expected: ""},
+		{args: []string{"-coverprofile=cover.out", "-v"}, expected: "cover.out"},
+		{args: []string{"--coverprofile", "cover.out"}, expected: "cover.out"},
+		{args: []string{"-coverprofile"}, expected: ""},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, coverProfileArg(tc.args), tc.expected, tc.args)
+	}
+}
+
+func TestWithCoverProfile(t *testing.T) {
+	opts := &options{args: []string{"-v", "-coverprofile=cover.out"}}
+	assert.DeepEqual(t, withCoverProfile(opts, "other.out").args,
+		[]string{"-v", "-coverprofile=other.out"})
+	assert.DeepEqual(t, opts.args, []string{"-v", "-coverprofile=cover.out"})
+
+	opts = &options{args: []string{"-coverprofile", "cover.out", "-v"}}
+	assert.DeepEqual(t, withCoverProfile(opts, "other.out").args,
+		[]string{"-coverprofile", "other.out", "-v"})
+}
+
+func TestRun_WithShards_MergesCoverProfiles(t *testing.T) {
+	t.Run("no existing profile", func(t *testing.T) {
+		dir := fs.NewDir(t, t.Name())
+		defer dir.Remove()
+		runWithShardsMergesCoverProfiles(t, dir)
+	})
+	t.Run("replaces profile from an earlier run", func(t *testing.T) {
+		dir := fs.NewDir(t, t.Name(),
+			fs.WithFile("cover.out", "mode: set\npkg/old/a.go:3.20,5.2 3 1\n"))
+		defer dir.Remove()
+		runWithShardsMergesCoverProfiles(t, dir)
+	})
+}
+
+func runWithShardsMergesCoverProfiles(t *testing.T, dir *fs.Dir) {
+	t.Helper()
+
+	reset := patchListPackagesFn(func(patterns []string) []string {
+		return []string{"pkg/one", "pkg/two"}
+	})
+	defer reset()
+
+	fn := func(args []string) proc {
+		filename := coverProfileArg(args)
+		pkg := args[len(args)-1]
+		profile := fmt.Sprintf("mode: set\n%[1]s/a.go:3.20,5.2 3 1\n%[1]s/a.go:7.20,9.2 1 0\n", pkg)
+		assert.NilError(t, ioutil.WriteFile(filename, []byte(profile), 0644))
+
+		stdout := fmt.Sprintf(`{"Package": "%[1]s", "Test": "TestOne", "Action": "run"}
+{"Package": "%[1]s", "Test": "TestOne", "Action": "pass"}
+{"Package": "%[1]s", "Action": "output", "Output": "coverage: 75.0%% of statements\n"}
+{"Package": "%[1]s", "Action": "pass"}
+`, pkg)
+		return proc{
+			cmd:    fakeWaiter{},
+			stdout: strings.NewReader(stdout),
+			stderr: strings.NewReader(""),
+		}
+	}
+	defer patchStartGoTestFn(fn)()
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		format:      "testname",
+		args:        []string{"-coverprofile=" + dir.Join("cover.out")},
+		packages:    []string{"./..."},
+		shards:      2,
+		coverageMin: 80,
+		stdout:      out,
+		stderr:      new(bytes.Buffer),
+		hideSummary: newHideSummaryValue(),
+	}
+	err := run(opts)
+	assert.Error(t, err, `coverage is below the minimum:
+  pkg/one 75.0% < 80.0%
+  pkg/two 75.0% < 80.0%
+  total 75.0% < 80.0%`)
+	assert.Assert(t, strings.Contains(out.String(), " 75.0%  total\n"), out.String())
+
+	raw, err := ioutil.ReadFile(dir.Join("cover.out"))
+	assert.NilError(t, err)
+	expected := `mode: set
+pkg/one/a.go:3.20,5.2 3 1
+pkg/one/a.go:7.20,9.2 1 0
+pkg/two/a.go:3.20,5.2 3 1
+pkg/two/a.go:7.20,9.2 1 0
+`
+	assert.Equal(t, string(raw), expected)
+	assert.Assert(t, fs.Equal(dir.Path(), fs.Expected(t,
+		fs.WithFile("cover.out", expected, fs.MatchAnyFileMode))))
+}
+
+func TestCoverageMinErr_WithCoverageMinFile(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("coverage-min", `
+# legacy packages have less coverage
+example.com/pkg/legacy/* 20
+example.com/pkg/strict 90%
+`))
+	defer dir.Remove()
+
+	source := `{"Package": "example.com/pkg/legacy/old", "Action": "output", "Output": "coverage: 25.0% of statements\n"}
+{"Package": "example.com/pkg/legacy/old", "Action": "pass"}
+{"Package": "example.com/pkg/strict", "Action": "output", "Output": "coverage: 85.0% of statements\n"}
+{"Package": "example.com/pkg/strict", "Action": "pass"}
+{"Package": "example.com/pkg/other", "Action": "output", "Output": "coverage: 55.0% of statements\n"}
+{"Package": "example.com/pkg/other", "Action": "pass"}
+`
+	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{Stdout: strings.NewReader(source)})
+	assert.NilError(t, err)
+
+	minimums, err := readCoverageMinFile(dir.Join("coverage-min"))
+	assert.NilError(t, err)
+	opts := &options{coverageMin: 50, coverageMinimums: minimums}
+	err = coverageMinErr(opts, exec)
+	assert.Error(t, err, "coverage is below the minimum:\n  example.com/pkg/strict 85.0% < 90.0%")
+}
+
+func TestCoverageMinErr_NoCoverageData(t *testing.T) {
+	source := `{"Package": "example.com/pkg/one", "Action": "output", "Output": "coverage: 85.0% of statements\n"}
+{"Package": "example.com/pkg/one", "Action": "pass"}
+{"Package": "example.com/pkg/two", "Action": "pass"}
+{"Package": "example.com/pkg/legacy", "Action": "pass"}
+`
+	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{Stdout: strings.NewReader(source)})
+	assert.NilError(t, err)
+
+	opts := &options{
+		coverageMin:      50,
+		coverageMinimums: coverageMinimums{{pattern: "example.com/pkg/legacy", percent: 0}},
+	}
+	err = coverageMinErr(opts, exec)
+	assert.Error(t, err, "coverage is below the minimum:\n  example.com/pkg/two has no coverage data")
+}
+
+func TestRun_WithInvalidCoverageMinFile(t *testing.T) {
+	file := fs.NewFile(t, t.Name(), fs.WithContent("example.com/pkg\n"))
+	defer file.Remove()
+
+	fn := func(args []string) proc {
+		t.Fatalf("go test should not be run when the coverage minimum file is invalid")
+		return proc{}
+	}
+	defer patchStartGoTestFn(fn)()
+
+	opts := &options{
+		format:          "testname",
+		coverageMinFile: file.Path(),
+		stdout:          new(bytes.Buffer),
+		stderr:          new(bytes.Buffer),
+		hideSummary:     newHideSummaryValue(),
+	}
+	err := run(opts)
+	assert.Error(t, err,
+		`invalid coverage minimum "example.com/pkg": expected a package and a percentage`)
+}
+
+func TestRun_WithCoverageReports(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+
+	var goTestArgs []string
+	fn := func(args []string) proc {
+		goTestArgs = args
+		filename := coverProfileArg(args)
+		assert.Assert(t, filename != "", args)
+		profile := "mode: set\nexample.com/pkg/a.go:3.20,5.2 3 1\nexample.com/pkg/a.go:7.20,7.30 1 0\n"
+		assert.NilError(t, ioutil.WriteFile(filename, []byte(profile), 0644))
+
+		stdout := `{"Package": "example.com/pkg", "Test": "TestOne", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestOne", "Action": "pass"}
+{"Package": "example.com/pkg", "Action": "pass"}
+`
+		return proc{
+			cmd:    fakeWaiter{},
+			stdout: strings.NewReader(stdout),
+			stderr: strings.NewReader(""),
+		}
+	}
+	defer patchStartGoTestFn(fn)()
+
+	opts := &options{
+		format:        "testname",
+		args:          []string{"-v"},
+		coberturaFile: dir.Join("cobertura.xml"),
+		lcovFile:      dir.Join("lcov.info"),
+		stdout:        new(bytes.Buffer),
+		stderr:        new(bytes.Buffer),
+		hideSummary:   newHideSummaryValue(),
+	}
+	assert.NilError(t, run(opts))
+
+	// the temporary coverage profile is removed at the end of the run
+	_, err := ioutil.ReadFile(coverProfileArg(goTestArgs))
+	assert.Assert(t, err != nil)
+
+	raw, err := ioutil.ReadFile(dir.Join("lcov.info"))
+	assert.NilError(t, err)
+	expected := `TN:
+SF:example.com/pkg/a.go
+DA:3,1
+DA:4,1
+DA:5,1
+DA:7,0
+LF:4
+LH:3
+end_of_record
+`
+	assert.Equal(t, string(raw), expected)
+
+	raw, err = ioutil.ReadFile(dir.Join("cobertura.xml"))
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(raw),
+		`<class name="a.go" filename="example.com/pkg/a.go" line-rate="0.7500"`), string(raw))
+}
+
+func TestOptions_Validate_CoverageReportsWithRawCommand(t *testing.T) {
+	opts := &options{
+		rawCommand: true,
+		lcovFile:   "lcov.info",
+		args:       []string{"./test.sh"},
+	}
+	err := opts.Validate()
+	assert.Error(t, err, "--cobertura and --lcov require the -coverprofile flag when used with --raw-command")
+
+	opts.args = append(opts.args, "-coverprofile=cover.out")
+	assert.NilError(t, opts.Validate())
+}
@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"encoding/csv"
+	"fmt"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/google/shlex"
@@ -87,6 +89,39 @@ func (f *junitFieldFormatValue) Value() junitxml.FormatFunc {
 	return f.value
 }
 
+var junitSubtestsValues = "flat, nested, classname"
+
+type junitSubtestsValue struct {
+	value junitxml.SubtestFormat
+}
+
+func (f *junitSubtestsValue) Set(val string) error {
+	switch format := junitxml.SubtestFormat(val); format {
+	case junitxml.SubtestsFlat, junitxml.SubtestsNested, junitxml.SubtestsClassname:
+		f.value = format
+		return nil
+	}
+	return errors.Errorf("invalid value: %v, must be one of: "+junitSubtestsValues, val)
+}
+
+func (f *junitSubtestsValue) Type() string {
+	return "subtests"
+}
+
+func (f *junitSubtestsValue) String() string {
+	if f == nil || f.value == "" {
+		return string(junitxml.SubtestsFlat)
+	}
+	return string(f.value)
+}
+
+func (f *junitSubtestsValue) Value() junitxml.SubtestFormat {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
 type commandValue struct {
 	original string
 	command  []string
@@ -132,3 +167,46 @@ func (s *stringSlice) Set(raw string) error {
 func (s *stringSlice) Type() string {
 	return "list"
 }
+
+// partitionValue is a flag.Value which parses a value in the form INDEX/TOTAL,
+// where INDEX is a number between 1 and TOTAL.
+type partitionValue struct {
+	index int
+	total int
+}
+
+func (p *partitionValue) String() string {
+	if p.total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", p.index, p.total)
+}
+
+func (p *partitionValue) Set(raw string) error {
+	parts := strings.SplitN(raw, "/", 2)
+	if len(parts) != 2 {
+		return errors.Errorf("invalid value: %v, must be in the form INDEX/TOTAL", raw)
+	}
+	index, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return errors.Wrapf(err, "invalid partition index")
+	}
+	total, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return errors.Wrapf(err, "invalid partition total")
+	}
+	if total < 1 || index < 1 || index > total {
+		return errors.Errorf("invalid value: %v, INDEX must be between 1 and TOTAL", raw)
+	}
+	p.index, p.total = index, total
+	return nil
+}
+
+func (p *partitionValue) Type() string {
+	return "index/total"
+}
+
+// IsSet returns true if the flag was set to a valid partition.
+func (p *partitionValue) IsSet() bool {
+	return p != nil && p.total > 0
+}
@@ -33,3 +33,21 @@ func TestStringSlice(t *testing.T) {
 	assert.NilError(t, ss.Set(value))
 	assert.DeepEqual(t, v, []string{"one", "two", "three", "four", "five"})
 }
+
+func TestPartitionValue_Set(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		value := &partitionValue{}
+		assert.NilError(t, value.Set("2/8"))
+		assert.Equal(t, value.String(), "2/8")
+		assert.Assert(t, value.IsSet())
+	})
+	t.Run("missing total", func(t *testing.T) {
+		value := &partitionValue{}
+		assert.ErrorContains(t, value.Set("2"), "must be in the form INDEX/TOTAL")
+	})
+	t.Run("index out of range", func(t *testing.T) {
+		value := &partitionValue{}
+		assert.ErrorContains(t, value.Set("0/3"), "INDEX must be between 1 and TOTAL")
+		assert.ErrorContains(t, value.Set("4/3"), "INDEX must be between 1 and TOTAL")
+	})
+}
@@ -1,14 +1,20 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 
 	"github.com/pkg/errors"
+	"gotest.tools/gotestsum/internal/benchjson"
+	"gotest.tools/gotestsum/internal/history"
+	"gotest.tools/gotestsum/internal/htmlreport"
 	"gotest.tools/gotestsum/internal/junitxml"
+	"gotest.tools/gotestsum/internal/markdown"
 	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/summaryjson"
 	"gotest.tools/gotestsum/testjson"
 )
 
@@ -16,6 +22,8 @@ type eventHandler struct {
 	formatter testjson.EventFormatter
 	err       io.Writer
 	jsonFile  io.WriteCloser
+	// flushed is true once the formatter has been flushed.
+	flushed bool
 }
 
 func (h *eventHandler) Err(text string) error {
@@ -40,7 +48,34 @@ func (h *eventHandler) Event(event testjson.TestEvent, execution *testjson.Execu
 	return nil
 }
 
+// Flush the formatter. Formatters which print output after the last event
+// implement io.Closer. The formatter is only flushed once.
+func (h *eventHandler) Flush() error {
+	if h.flushed {
+		return nil
+	}
+	h.flushed = true
+	if closer, ok := h.formatter.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// clearFooter clears the footer printed by the status format, so that other
+// output can be written to the terminal.
+func (h *eventHandler) clearFooter() error {
+	if clearer, ok := h.formatter.(interface{ ClearFooter() error }); ok {
+		return clearer.ClearFooter()
+	}
+	return nil
+}
+
+// Close flushes the formatter, if it was not already flushed by finishRun, and
+// closes the JSON file.
 func (h *eventHandler) Close() error {
+	if err := h.Flush(); err != nil {
+		log.Errorf("Failed to flush formatter: %v", err)
+	}
 	if h.jsonFile != nil {
 		if err := h.jsonFile.Close(); err != nil {
 			log.Errorf("Failed to close JSON file: %v", err)
@@ -51,8 +86,27 @@ func (h *eventHandler) Close() error {
 
 var _ testjson.EventHandler = &eventHandler{}
 
+// footerClearer is implemented by the EventHandlers which can clear the footer
+// printed by the status format.
+type footerClearer interface {
+	clearFooter() error
+}
+
+// clearFooter clears the footer printed by the status format, if handler
+// prints one. Must be called before writing directly to stdout or stderr while
+// the tests are running.
+func clearFooter(handler testjson.EventHandler) {
+	clearer, ok := handler.(footerClearer)
+	if !ok {
+		return
+	}
+	if err := clearer.clearFooter(); err != nil {
+		log.Warnf("Failed to clear the status footer: %v", err)
+	}
+}
+
 func newEventHandler(opts *options) (*eventHandler, error) {
-	formatter := testjson.NewEventFormatter(opts.stdout, opts.format)
+	formatter := newEventFormatter(opts)
 	if formatter == nil {
 		return nil, errors.Errorf("unknown format %s", opts.format)
 	}
@@ -70,6 +124,37 @@ func newEventHandler(opts *options) (*eventHandler, error) {
 	return handler, nil
 }
 
+func newEventFormatter(opts *options) testjson.EventFormatter {
+	if opts.format == "status" {
+		return testjson.NewStatusFormatter(opts.stdout, expectedTestCount(opts))
+	}
+	return testjson.NewEventFormatter(opts.stdout, opts.format)
+}
+
+// expectedTestCount returns the number of tests in the last run recorded in
+// the history file. Returns 0 if there is no history file.
+func expectedTestCount(opts *options) int {
+	if opts.historyFile == "" {
+		return 0
+	}
+	fh, err := os.Open(opts.historyFile)
+	switch {
+	case os.IsNotExist(err):
+		return 0
+	case err != nil:
+		log.Warnf("Failed to read history file: %v", err)
+		return 0
+	}
+	defer fh.Close() // nolint: errcheck
+
+	records, err := history.Read(fh)
+	if err != nil {
+		log.Warnf("Failed to read history file: %v", err)
+		return 0
+	}
+	return history.LastRunTotal(records)
+}
+
 func writeJUnitFile(opts *options, execution *testjson.Execution) error {
 	if opts.junitFile == "" {
 		return nil
@@ -87,16 +172,113 @@ func writeJUnitFile(opts *options, execution *testjson.Execution) error {
 	return junitxml.Write(junitFile, execution, junitxml.Config{
 		FormatTestSuiteName:     opts.junitTestSuiteNameFormat.Value(),
 		FormatTestCaseClassname: opts.junitTestCaseClassnameFormat.Value(),
+		SystemOut:               opts.junitSystemOut,
+		GroupReruns:             opts.junitGroupReruns,
+		Subtests:                opts.junitSubtests.Value(),
+		Extended:                opts.junitExtended,
 	})
 }
 
+func writeTAPFile(opts *options, execution *testjson.Execution) error {
+	if opts.tapFile == "" {
+		return nil
+	}
+	tapFile, err := os.Create(opts.tapFile)
+	if err != nil {
+		return fmt.Errorf("failed to open TAP file: %v", err)
+	}
+	defer func() {
+		if err := tapFile.Close(); err != nil {
+			log.Errorf("Failed to close TAP file: %v", err)
+		}
+	}()
+	return testjson.WriteTAP(tapFile, execution)
+}
+
+func writeHTMLFile(opts *options, execution *testjson.Execution) error {
+	if opts.htmlFile == "" {
+		return nil
+	}
+	htmlFile, err := os.Create(opts.htmlFile)
+	if err != nil {
+		return fmt.Errorf("failed to open HTML file: %v", err)
+	}
+	defer func() {
+		if err := htmlFile.Close(); err != nil {
+			log.Errorf("Failed to close HTML file: %v", err)
+		}
+	}()
+	return htmlreport.Write(htmlFile, execution)
+}
+
+func writeMarkdownFile(opts *options, execution *testjson.Execution) error {
+	if opts.markdownFile == "" {
+		return nil
+	}
+	markdownFile, err := os.Create(opts.markdownFile)
+	if err != nil {
+		return fmt.Errorf("failed to open markdown file: %v", err)
+	}
+	defer func() {
+		if err := markdownFile.Close(); err != nil {
+			log.Errorf("Failed to close markdown file: %v", err)
+		}
+	}()
+	return markdown.Write(markdownFile, execution, markdown.Config{})
+}
+
+func writeHistoryFile(opts *options, execution *testjson.Execution) error {
+	if opts.historyFile == "" {
+		return nil
+	}
+	return history.Append(opts.historyFile, execution)
+}
+
+func writeSummaryJSONFile(opts *options, execution *testjson.Execution) error {
+	if opts.summaryJSONFile == "" {
+		return nil
+	}
+	summaryFile, err := os.Create(opts.summaryJSONFile)
+	if err != nil {
+		return fmt.Errorf("failed to open JSON summary file: %v", err)
+	}
+	defer func() {
+		if err := summaryFile.Close(); err != nil {
+			log.Errorf("Failed to close JSON summary file: %v", err)
+		}
+	}()
+	return summaryjson.Write(summaryFile, execution)
+}
+
+func writeBenchJSONFile(opts *options, execution *testjson.Execution) error {
+	if opts.benchJSONFile == "" {
+		return nil
+	}
+	benchFile, err := os.Create(opts.benchJSONFile)
+	if err != nil {
+		return fmt.Errorf("failed to open benchmark JSON file: %v", err)
+	}
+	defer func() {
+		if err := benchFile.Close(); err != nil {
+			log.Errorf("Failed to close benchmark JSON file: %v", err)
+		}
+	}()
+	return benchjson.Write(benchFile, execution)
+}
+
 func postRunHook(opts *options, execution *testjson.Execution) error {
 	command := opts.postRunHookCmd.Value()
 	if len(command) == 0 {
 		return nil
 	}
 
+	stdin := new(bytes.Buffer)
+	if err := summaryjson.Write(stdin, execution); err != nil {
+		return err
+	}
+
 	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = stdin
 	cmd.Stdout = opts.stdout
 	cmd.Stderr = opts.stderr
 	cmd.Env = append(
@@ -108,6 +290,5 @@ func postRunHook(opts *options, execution *testjson.Execution) error {
 		fmt.Sprintf("TESTS_SKIPPED=%d", len(execution.Skipped())),
 		fmt.Sprintf("TESTS_ERRORS=%d", len(execution.Errors())),
 	)
-	// TODO: send a more detailed report to stdin?
 	return cmd.Run()
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
@@ -9,6 +10,7 @@ import (
 	"gotest.tools/gotestsum/testjson"
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
 	"gotest.tools/v3/golden"
 )
 
@@ -71,3 +73,54 @@ func TestEventHandler_Event_WithMissingActionFail(t *testing.T) {
 	// of the formatter.
 	golden.Assert(t, errBuf.String(), "event-handler-missing-test-fail-expected")
 }
+
+// footerFormatter records the calls to the methods of the formatter in out.
+type footerFormatter struct {
+	out *bytes.Buffer
+}
+
+func (f footerFormatter) Format(event testjson.TestEvent, _ *testjson.Execution) error {
+	f.out.WriteString("event " + string(event.Action) + "\n")
+	return nil
+}
+
+func (f footerFormatter) ClearFooter() error {
+	f.out.WriteString("clear footer\n")
+	return nil
+}
+
+func (f footerFormatter) Close() error {
+	f.out.WriteString("close\n")
+	return nil
+}
+
+func TestEventHandler_Close_FlushesFormatterOnce(t *testing.T) {
+	out := new(bytes.Buffer)
+	handler := &eventHandler{formatter: footerFormatter{out: out}}
+	assert.NilError(t, handler.Close())
+	assert.NilError(t, handler.Flush())
+	assert.Equal(t, out.String(), "close\n")
+}
+
+func TestWriteGitHubStepSummary(t *testing.T) {
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+	filename := dir.Join("step-summary.md")
+	defer env.Patch(t, "GITHUB_STEP_SUMMARY", filename)()
+
+	exec := newExecFromTestData(t)
+	opts := &options{format: "github-actions"}
+	assert.NilError(t, writeGitHubStepSummary(opts, exec))
+
+	raw, err := ioutil.ReadFile(filename)
+	assert.NilError(t, err)
+	golden.Assert(t, string(raw), "github-step-summary-expected")
+
+	t.Run("other formats do not write a summary", func(t *testing.T) {
+		assert.NilError(t, os.Remove(filename))
+		opts := &options{format: "short"}
+		assert.NilError(t, writeGitHubStepSummary(opts, exec))
+		_, err := os.Stat(filename)
+		assert.Assert(t, os.IsNotExist(err))
+	})
+}
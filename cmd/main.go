@@ -8,6 +8,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
@@ -46,7 +47,7 @@ func runWatcher(opts *options) error {
 		opts := *opts
 		opts.packages = []string{pkg}
 		err := run(&opts)
-		if !isExitCoder(err) {
+		if !IsExitCoder(err) {
 			return err
 		}
 		return nil
@@ -59,7 +60,9 @@ func setupFlags(name string) (*pflag.FlagSet, *options) {
 		hideSummary:                  newHideSummaryValue(),
 		junitTestCaseClassnameFormat: &junitFieldFormatValue{},
 		junitTestSuiteNameFormat:     &junitFieldFormatValue{},
+		junitSubtests:                &junitSubtestsValue{},
 		postRunHookCmd:               &commandValue{},
+		partition:                    &partitionValue{},
 		stdout:                       os.Stdout,
 		stderr:                       os.Stderr,
 	}
@@ -95,12 +98,40 @@ func setupFlags(name string) (*pflag.FlagSet, *options) {
 		"format the testsuite name field as: "+junitFieldFormatValues)
 	flags.Var(opts.junitTestCaseClassnameFormat, "junitfile-testcase-classname",
 		"format the testcase classname field as: "+junitFieldFormatValues)
+	flags.BoolVar(&opts.junitSystemOut, "junitfile-system-out", false,
+		"include the output of every testcase in a system-out element")
+	flags.BoolVar(&opts.junitExtended, "junitfile-extended", false,
+		"add errors, skipped, timestamp, and hostname attributes, and report build failures and TestMain as errors")
+	flags.BoolVar(&opts.junitGroupReruns, "junitfile-group-reruns", false,
+		"report every attempt of a re-run test as a single testcase, with flakyFailure or rerunFailure elements")
+	flags.Var(opts.junitSubtests, "junitfile-subtests",
+		"format subtests as: "+junitSubtestsValues)
+	flags.StringVar(&opts.tapFile, "tapfile",
+		lookEnvWithDefault("GOTESTSUM_TAPFILE", ""),
+		"write a TAP version 13 file")
+	flags.StringVar(&opts.htmlFile, "htmlfile",
+		lookEnvWithDefault("GOTESTSUM_HTMLFILE", ""),
+		"write an HTML report file")
+	flags.StringVar(&opts.markdownFile, "markdownfile",
+		lookEnvWithDefault("GOTESTSUM_MARKDOWNFILE", ""),
+		"write a markdown report file, for use in a pull request comment")
+	flags.StringVar(&opts.historyFile, "historyfile",
+		lookEnvWithDefault("GOTESTSUM_HISTORYFILE", ""),
+		"append a record of every test case to a history file")
+	flags.StringVar(&opts.summaryJSONFile, "summary-json",
+		lookEnvWithDefault("GOTESTSUM_SUMMARY_JSON", ""),
+		"write a JSON summary of the test run to file")
+	flags.StringVar(&opts.benchJSONFile, "bench-json",
+		lookEnvWithDefault("GOTESTSUM_BENCH_JSON", ""),
+		"write the benchmark results to a JSON file")
 
 	flags.IntVar(&opts.rerunFailsMaxAttempts, "rerun-fails", 0,
 		"rerun failed tests until they all pass, or attempts exceeds maximum. Defaults to max 2 reruns when enabled.")
 	flags.Lookup("rerun-fails").NoOptDefVal = "2"
 	flags.IntVar(&opts.rerunFailsMaxInitialFailures, "rerun-fails-max-failures", 10,
 		"do not rerun any tests if the initial run has more than this number of failures")
+	flags.StringVar(&opts.quarantineFile, "quarantine", "",
+		"path to a file with a list of tests which may fail without failing the run")
 	flags.Var((*stringSlice)(&opts.packages), "packages",
 		"space separated list of package to test")
 	flags.StringVar(&opts.rerunFailsReportFile, "rerun-fails-report", "",
@@ -108,6 +139,31 @@ func setupFlags(name string) (*pflag.FlagSet, *options) {
 	flags.BoolVar(&opts.rerunFailsOnlyRootCases, "rerun-fails-only-root-testcases", false,
 		"rerun only root testcaes, instead of only subtests")
 	flags.Lookup("rerun-fails-only-root-testcases").Hidden = true
+	flags.IntVar(&opts.shards, "shards", 0,
+		"split the packages into n groups, and run 'go test' for each group concurrently")
+	flags.Var(opts.partition, "partition",
+		"run only the packages assigned to partition INDEX of TOTAL partitions")
+	flags.StringVar(&opts.partitionTimingsFile, "partition-timings", "",
+		"path to a jsonfile from a previous run, used to balance partitions by elapsed time")
+
+	flags.StringVar(&opts.coberturaFile, "cobertura",
+		lookEnvWithDefault("GOTESTSUM_COBERTURA", ""),
+		"write a Cobertura XML coverage report file")
+	flags.StringVar(&opts.lcovFile, "lcov",
+		lookEnvWithDefault("GOTESTSUM_LCOV", ""),
+		"write an LCOV coverage report file")
+	flags.Float64Var(&opts.coverageMin, "coverage-min", 0,
+		"fail the run when the coverage of any package, or the total coverage, is below this percentage")
+	flags.StringVar(&opts.coverageMinFile, "coverage-min-file", "",
+		"path to a file with the minimum coverage percentage for packages, overrides --coverage-min")
+	flags.DurationVar(&opts.testStallTimeout, "test-stall-timeout", 0,
+		"print the tests which have been running for longer than this duration, with their output")
+	flags.BoolVar(&opts.testStallQuit, "test-stall-sigquit", false,
+		"send SIGQUIT to the test binaries when tests stall, to print a goroutine dump")
+	flags.StringVar(&opts.replayFile, "replay", "",
+		"read test2json output from a file, instead of running 'go test'")
+	flags.Float64Var(&opts.replaySpeed, "replay-speed", 0,
+		"wait for the time between events divided by speed when using --replay. 0 replays without waiting")
 
 	flags.BoolVar(&opts.debug, "debug", false, "enabled debug logging")
 	flags.BoolVar(&opts.version, "version", false, "show version and exit")
@@ -129,9 +185,13 @@ Formats:
     dots-v2                 experimental dots format, one package per line
     pkgname                 print a line for each package
     pkgname-and-test-fails  print a line for each package and failed test output
+    github-actions          print a group for each package, and annotations for failed tests
+    teamcity                print TeamCity service messages for each test
+    tap                     print a TAP version 13 subtest for each package
     testname                print a line for each test and package
     standard-quiet          standard go test format
     standard-verbose        standard go test -v format
+    status                  print a line for each test and package, and the running tests
 
 Commands:
     tool                    tools for working with test2json output
@@ -152,19 +212,51 @@ type options struct {
 	rawCommand                   bool
 	jsonFile                     string
 	junitFile                    string
+	tapFile                      string
+	htmlFile                     string
+	markdownFile                 string
+	historyFile                  string
+	summaryJSONFile              string
+	benchJSONFile                string
 	postRunHookCmd               *commandValue
 	noColor                      bool
 	hideSummary                  *hideSummaryValue
 	junitTestSuiteNameFormat     *junitFieldFormatValue
 	junitTestCaseClassnameFormat *junitFieldFormatValue
+	junitSystemOut               bool
+	junitExtended                bool
+	junitGroupReruns             bool
+	junitSubtests                *junitSubtestsValue
 	rerunFailsMaxAttempts        int
 	rerunFailsMaxInitialFailures int
 	rerunFailsReportFile         string
 	rerunFailsOnlyRootCases      bool
 	packages                     []string
+	quarantineFile               string
+	shards                       int
+	partition                    *partitionValue
+	partitionTimingsFile         string
 	watch                        bool
+	replayFile                   string
+	coverageMin                  float64
+	coverageMinFile              string
+	coberturaFile                string
+	lcovFile                     string
+	replaySpeed                  float64
+	testStallTimeout             time.Duration
+	testStallQuit                bool
 	version                      bool
 
+	// coverProfile is the coverage profile written by 'go test' when a coverage
+	// report is requested without the -coverprofile flag.
+	coverProfile string
+	// goTestProcs is the set of running 'go test' processes, when they are
+	// started in their own process group by --test-stall-sigquit.
+	goTestProcs *goTestProcs
+	// coverageMinimums are read from coverageMinFile before 'go test' is
+	// started.
+	coverageMinimums coverageMinimums
+
 	// shims for testing
 	stdout io.Writer
 	stderr io.Writer
@@ -176,6 +268,52 @@ func (o options) Validate() error {
 			"when go test args are used with --rerun-fails-max-attempts " +
 				"the list of packages to test must be specified by the --packages flag")
 	}
+	if o.shards > 1 && o.rawCommand {
+		return fmt.Errorf("--shards can not be used with --raw-command")
+	}
+	if o.shards > 1 && len(o.args) > 0 && len(o.packages) == 0 {
+		return fmt.Errorf(
+			"when go test args are used with --shards " +
+				"the list of packages to test must be specified by the --packages flag")
+	}
+	if o.partition.IsSet() && o.rawCommand {
+		return fmt.Errorf("--partition can not be used with --raw-command")
+	}
+	if o.replayFile != "" {
+		switch {
+		case o.rerunFailsMaxAttempts > 0:
+			return fmt.Errorf("--replay can not be used with --rerun-fails")
+		case o.shards > 1:
+			return fmt.Errorf("--replay can not be used with --shards")
+		case o.partition.IsSet():
+			return fmt.Errorf("--replay can not be used with --partition")
+		case o.watch:
+			return fmt.Errorf("--replay can not be used with --watch")
+		}
+	}
+	if o.coverageMin < 0 || o.coverageMin > 100 {
+		return fmt.Errorf("--coverage-min must be between 0 and 100")
+	}
+	if (o.coberturaFile != "" || o.lcovFile != "") && o.rawCommand && coverProfileArg(o.args) == "" {
+		return fmt.Errorf("--cobertura and --lcov require the -coverprofile flag when used with --raw-command")
+	}
+	if o.testStallTimeout < 0 {
+		return fmt.Errorf("--test-stall-timeout must not be negative")
+	}
+	if o.testStallQuit && o.testStallTimeout == 0 {
+		return fmt.Errorf("--test-stall-sigquit requires --test-stall-timeout")
+	}
+	if o.testStallQuit && !processGroupSupported {
+		return fmt.Errorf("--test-stall-sigquit is not supported on this platform")
+	}
+	if o.replaySpeed < 0 {
+		return fmt.Errorf("--replay-speed must not be negative")
+	}
+	if o.partition.IsSet() && len(o.args) > 0 && len(o.packages) == 0 {
+		return fmt.Errorf(
+			"when go test args are used with --partition " +
+				"the list of packages to test must be specified by the --packages flag")
+	}
 	return nil
 }
 
@@ -193,32 +331,87 @@ func run(opts *options) error {
 	if err := opts.Validate(); err != nil {
 		return err
 	}
+	if opts.coverageMinFile != "" {
+		minimums, err := readCoverageMinFile(opts.coverageMinFile)
+		if err != nil {
+			return err
+		}
+		minOpts := *opts
+		minOpts.coverageMinimums = minimums
+		opts = &minOpts
+	}
 
-	goTestProc, err := startGoTestFn(ctx, goTestCmdArgs(opts, rerunOpts{}))
+	var emptyPartition bool
+	if opts.partition.IsSet() {
+		pkgs, err := partitionPackages(ctx, opts)
+		if err != nil {
+			return err
+		}
+		partOpts := *opts
+		partOpts.packages = pkgs
+		opts = &partOpts
+		if len(pkgs) == 0 {
+			log.Warnf("No packages assigned to partition %s", opts.partition)
+			emptyPartition = true
+		}
+	}
+
+	coverProfile, removeCoverProfile, err := newCoverProfileFile(opts)
 	if err != nil {
 		return err
 	}
+	defer removeCoverProfile()
+	if coverProfile != "" {
+		coverOpts := *opts
+		coverOpts.coverProfile = coverProfile
+		opts = &coverOpts
+	}
+	if opts.testStallQuit {
+		procOpts := *opts
+		procOpts.goTestProcs = newGoTestProcs()
+		opts = &procOpts
+	}
 
 	handler, err := newEventHandler(opts)
 	if err != nil {
 		return err
 	}
 	defer handler.Close() // nolint: errcheck
-	cfg := testjson.ScanConfig{
-		Stdout:  goTestProc.stdout,
-		Stderr:  goTestProc.stderr,
-		Handler: handler,
+
+	exec := testjson.NewExecution()
+	if opts.quarantineFile != "" {
+		quarantine, err := readQuarantineFile(opts.quarantineFile)
+		if err != nil {
+			return err
+		}
+		exec.SetQuarantine(quarantine.match)
+	}
+	exec.SetKeepPassedOutput(opts.junitFile != "" && opts.junitSystemOut)
+	cfg := testjson.ScanConfig{Execution: exec, Handler: handler}
+	if emptyPartition {
+		// There are no tests to run, but the summary and reports are still
+		// written for an empty run, so that every partition has them.
+		cfg.Stdout = strings.NewReader("")
+		if _, err := testjson.ScanTestOutput(cfg); err != nil {
+			return err
+		}
+		return finishRun(opts, handler, exec, nil)
+	}
+	if opts.testStallTimeout > 0 {
+		watchdog := newStallWatchdog(opts, handler)
+		go watchdog.watch(ctx)
+		cfg.Handler = watchdog
 	}
-	exec, err := testjson.ScanTestOutput(cfg)
+	goTest, err := startAndScan(ctx, opts, cfg)
 	if err != nil {
 		return err
 	}
-	exitErr := goTestProc.cmd.Wait()
+	exitErr := goTest.Wait()
 	if exitErr == nil || opts.rerunFailsMaxAttempts == 0 {
-		return finishRun(opts, exec, exitErr)
+		return finishRun(opts, handler, exec, exitErr)
 	}
 	if err := hasErrors(exitErr, exec); err != nil {
-		return finishRun(opts, exec, err)
+		return finishRun(opts, handler, exec, err)
 	}
 
 	failed := len(rerunFailsFilter(opts)(exec.Failed()))
@@ -226,26 +419,65 @@ func run(opts *options) error {
 		err := fmt.Errorf(
 			"number of test failures (%d) exceeds maximum (%d) set by --rerun-fails-max-failures",
 			failed, opts.rerunFailsMaxInitialFailures)
-		return finishRun(opts, exec, err)
+		return finishRun(opts, handler, exec, err)
 	}
 
-	cfg = testjson.ScanConfig{Execution: exec, Handler: handler}
 	exitErr = rerunFailed(ctx, opts, cfg)
 	if err := writeRerunFailsReport(opts, exec); err != nil {
 		return err
 	}
-	return finishRun(opts, exec, exitErr)
+	return finishRun(opts, handler, exec, exitErr)
 }
 
-func finishRun(opts *options, exec *testjson.Execution, exitErr error) error {
+func finishRun(opts *options, handler *eventHandler, exec *testjson.Execution, exitErr error) error {
+	if err := handler.Flush(); err != nil {
+		return fmt.Errorf("failed to flush formatter: %v", err)
+	}
+	exitErr = quarantineExitErr(exec, exitErr)
+	if err := setTotalCoverage(opts, exec); err != nil {
+		return err
+	}
 	testjson.PrintSummary(opts.stdout, exec, opts.hideSummary.value)
+	if err := coverageMinErr(opts, exec); err != nil && exitErr == nil {
+		exitErr = err
+	}
 
 	if err := writeJUnitFile(opts, exec); err != nil {
 		return err
 	}
-	if err := postRunHook(opts, exec); err != nil {
+	if err := writeTAPFile(opts, exec); err != nil {
+		return err
+	}
+	if err := writeHTMLFile(opts, exec); err != nil {
+		return err
+	}
+	if err := writeMarkdownFile(opts, exec); err != nil {
+		return err
+	}
+	// A replay is not a new run, so it is not added to the history, and does
+	// not run the post-run command.
+	if opts.replayFile == "" {
+		if err := writeHistoryFile(opts, exec); err != nil {
+			return err
+		}
+	}
+	if err := writeSummaryJSONFile(opts, exec); err != nil {
+		return err
+	}
+	if err := writeBenchJSONFile(opts, exec); err != nil {
+		return err
+	}
+	if err := writeCoverageReports(opts); err != nil {
+		return err
+	}
+	if err := writeGitHubStepSummary(opts, exec); err != nil {
 		return err
 	}
+	if opts.replayFile == "" {
+		if err := postRunHook(opts, exec); err != nil {
+			return err
+		}
+	}
 	return exitErr
 }
 
@@ -262,6 +494,9 @@ func goTestCmdArgs(opts *options, rerunOpts rerunOpts) []string {
 
 	if len(args) == 0 {
 		result = append(result, "-json")
+		if opts.coverProfile != "" {
+			result = append(result, "-coverprofile="+opts.coverProfile)
+		}
 		if rerunOpts.runFlag != "" {
 			result = append(result, rerunOpts.runFlag)
 		}
@@ -271,6 +506,9 @@ func goTestCmdArgs(opts *options, rerunOpts rerunOpts) []string {
 	if boolArgIndex("json", args) < 0 {
 		result = append(result, "-json")
 	}
+	if opts.coverProfile != "" && coverProfileArg(args) == "" {
+		result = append(result, "-coverprofile="+opts.coverProfile)
+	}
 
 	if rerunOpts.runFlag != "" {
 		// Remove any existing run arg, it needs to be replaced with our new one
@@ -346,12 +584,17 @@ type waiter interface {
 	Wait() error
 }
 
-func startGoTest(ctx context.Context, args []string) (proc, error) {
+// startGoTest starts the command in args. When procs is not nil the command is
+// started in a new process group, and added to procs until it exits.
+func startGoTest(ctx context.Context, procs *goTestProcs, args []string) (proc, error) {
 	if len(args) == 0 {
 		return proc{}, errors.New("missing command to run")
 	}
 
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	if procs != nil {
+		setProcessGroup(cmd)
+	}
 	p := proc{cmd: cmd}
 	log.Debugf("exec: %s", cmd.Args)
 	var err error
@@ -369,7 +612,16 @@ func startGoTest(ctx context.Context, args []string) (proc, error) {
 	log.Debugf("go test pid: %d", cmd.Process.Pid)
 
 	ctx, cancel := context.WithCancel(ctx)
-	newSignalHandler(ctx, cmd.Process.Pid)
+	newSignalHandler(ctx, cmd.Process.Pid, procs != nil)
+	if procs != nil {
+		pid := cmd.Process.Pid
+		procs.add(pid)
+		cancelCtx := cancel
+		cancel = func() {
+			procs.remove(pid)
+			cancelCtx()
+		}
+	}
 	p.cmd = &cancelWaiter{cancel: cancel, wrapped: p.cmd}
 	return p, nil
 }
@@ -392,12 +644,32 @@ type exitCoder interface {
 	ExitCode() int
 }
 
-func isExitCoder(err error) bool {
+// IsExitCoder returns true if err has an exit code, either because it is the
+// exit status of a process, or because the tests failed.
+func IsExitCoder(err error) bool {
 	_, ok := err.(exitCoder)
 	return ok
 }
 
-func newSignalHandler(ctx context.Context, pid int) {
+// failedError is returned when the tests failed, and there is no exit status
+// from a 'go test' process, for example when the events are read from a file.
+// The exit code is 1, the same as 'go test' when a test fails.
+type failedError struct {
+	msg string
+}
+
+func (e *failedError) Error() string {
+	return e.msg
+}
+
+func (e *failedError) ExitCode() int {
+	return 1
+}
+
+// newSignalHandler forwards an interrupt to the process. When group is true
+// the process was started in a new process group, and the interrupt is sent to
+// every process in the group.
+func newSignalHandler(ctx context.Context, pid int, group bool) {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 
@@ -408,6 +680,12 @@ func newSignalHandler(ctx context.Context, pid int) {
 		case <-ctx.Done():
 			return
 		case s := <-c:
+			if group {
+				if err := signalProcessGroup(pid, s); err != nil {
+					log.Errorf("failed to interrupt 'go test': %v", err)
+				}
+				return
+			}
 			proc, err := os.FindProcess(pid)
 			if err != nil {
 				log.Errorf("failed to find pid of 'go test': %v", err)
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
 	"gotest.tools/v3/golden"
 )
 
@@ -67,6 +69,20 @@ func TestOptions_Validate_FromFlags(t *testing.T) {
 			name: "rerun flag, no go-test args, with packages flag",
 			args: []string{"--rerun-fails", "--packages", "./..."},
 		},
+		{
+			name:     "shards flag, raw command",
+			args:     []string{"--shards", "2", "--raw-command", "--", "./test-all"},
+			expected: "--shards can not be used with --raw-command",
+		},
+		{
+			name:     "shards flag, go-test args, no packages flag",
+			args:     []string{"--shards", "2", "--", "./..."},
+			expected: "the list of packages to test must be specified by the --packages flag",
+		},
+		{
+			name: "shards flag, go-test args, with packages flag",
+			args: []string{"--shards", "2", "--packages", "./...", "--", "-count=1"},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -358,6 +374,51 @@ func TestRun_RerunFails_BuildErrorPreventsRerun(t *testing.T) {
 	assert.ErrorContains(t, err, "rerun aborted because previous run had errors", out.String())
 }
 
+func TestRun_WithTAPFormat_PrintsPlanBeforeSummary(t *testing.T) {
+	jsonPassed := `{"Package": "pkg", "Action": "run"}
+{"Package": "pkg", "Test": "TestOne", "Action": "run"}
+{"Package": "pkg", "Test": "TestOne", "Action": "pass"}
+{"Package": "pkg", "Action": "pass"}
+`
+	fn := func(args []string) proc {
+		return proc{
+			cmd:    fakeWaiter{},
+			stdout: strings.NewReader(jsonPassed),
+			stderr: bytes.NewReader(nil),
+		}
+	}
+	reset := patchStartGoTestFn(fn)
+	defer reset()
+
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		rawCommand:  true,
+		args:        []string{"./test.test"},
+		format:      "tap",
+		tapFile:     dir.Join("report.tap"),
+		stdout:      out,
+		stderr:      os.Stderr,
+		hideSummary: newHideSummaryValue(),
+	}
+	assert.NilError(t, run(opts))
+
+	expected := `TAP version 13
+# Subtest: pkg
+    1..1
+    ok 1 - TestOne
+ok 1 - pkg
+1..1
+`
+	assert.Assert(t, strings.HasPrefix(out.String(), expected), out.String())
+
+	raw, err := ioutil.ReadFile(dir.Join("report.tap"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), "TAP version 13\n1..1\n# Subtest: pkg\n    1..1\n    ok 1 - TestOne\nok 1 - pkg\n")
+}
+
 // type checking of os/exec.ExitError is done in a test file so that users
 // installing from source can continue to use versions prior to go1.12.
 var _ exitCoder = &exec.ExitError{}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"time"
+
+	"gotest.tools/gotestsum/internal/aggregate"
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// partitionPackages returns the list of packages assigned to the partition
+// selected by opts.partition.
+func partitionPackages(ctx context.Context, opts *options) ([]string, error) {
+	pkgs, err := listPackagesFn(ctx, cmdArgPackageList(opts, rerunOpts{}, "./..."))
+	if err != nil {
+		return nil, err
+	}
+	timings, err := readPackageTimings(opts.partitionTimingsFile)
+	if err != nil {
+		return nil, err
+	}
+	return selectPartition(pkgs, timings, *opts.partition), nil
+}
+
+// readPackageTimings reads the elapsed time of every package from a jsonfile.
+// The elapsed time of a package is the sum of the median elapsed time of each
+// root test case in the package.
+func readPackageTimings(filename string) (map[string]time.Duration, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partition timings: %v", err)
+	}
+	defer fh.Close() // nolint: errcheck
+
+	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{Stdout: fh})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan partition timings: %v", err)
+	}
+
+	timings := make(map[string]time.Duration)
+	for _, name := range exec.Packages() {
+		var elapsed time.Duration
+		for _, tc := range aggregate.ByElapsed(exec.Package(name).TestCases()) {
+			if !tc.Test.IsSubTest() && tc.Elapsed > 0 {
+				elapsed += tc.Elapsed
+			}
+		}
+		timings[name] = elapsed
+	}
+	return timings, nil
+}
+
+// selectPartition returns the packages assigned to the partition p.
+//
+// When there are timings for the packages, the packages are assigned to
+// partitions so that the total elapsed time of each partition is balanced.
+// Packages which have no timing are estimated to take the average time of all
+// the packages with a timing. When there are no timings, packages are assigned
+// to partitions using a hash of the package name.
+//
+// Every node must use the same list of packages and timings to select the same
+// partitions.
+func selectPartition(pkgs []string, timings map[string]time.Duration, p partitionValue) []string {
+	if len(timings) == 0 {
+		return selectPartitionByHash(pkgs, p)
+	}
+
+	var average time.Duration
+	for _, elapsed := range timings {
+		average += elapsed
+	}
+	average /= time.Duration(len(timings))
+
+	elapsed := make(map[string]time.Duration, len(pkgs))
+	for _, pkg := range pkgs {
+		d, ok := timings[pkg]
+		if !ok {
+			d = average
+		}
+		elapsed[pkg] = d
+	}
+
+	sorted := append([]string{}, pkgs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if elapsed[a] == elapsed[b] {
+			return a < b
+		}
+		return elapsed[a] > elapsed[b]
+	})
+
+	// Assign the slowest package to the partition with the smallest total time.
+	totals := make([]time.Duration, p.total)
+	var result []string
+	for _, pkg := range sorted {
+		next := 0
+		for i := range totals {
+			if totals[i] < totals[next] {
+				next = i
+			}
+		}
+		totals[next] += elapsed[pkg]
+		if next == p.index-1 {
+			result = append(result, pkg)
+		}
+	}
+	sort.Strings(result)
+	log.Debugf("partition %s has an estimated elapsed time of %s", p.String(), totals[p.index-1])
+	return result
+}
+
+func selectPartitionByHash(pkgs []string, p partitionValue) []string {
+	var result []string
+	for _, pkg := range pkgs {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(pkg))
+		if int(h.Sum32()%uint32(p.total)) == p.index-1 {
+			result = append(result, pkg)
+		}
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestSelectPartition_WithoutTimings(t *testing.T) {
+	pkgs := []string{"pkg/a", "pkg/b", "pkg/c", "pkg/d", "pkg/e", "pkg/f", "pkg/g"}
+
+	seen := make(map[string]int)
+	for i := 1; i <= 3; i++ {
+		p := partitionValue{index: i, total: 3}
+		selected := selectPartition(pkgs, nil, p)
+		assert.DeepEqual(t, selectPartition(pkgs, nil, p), selected)
+		for _, pkg := range selected {
+			seen[pkg]++
+		}
+	}
+	assert.Equal(t, len(seen), len(pkgs))
+	for pkg, count := range seen {
+		assert.Equal(t, count, 1, "package %v selected by more than one partition", pkg)
+	}
+}
+
+func TestSelectPartition_WithTimings(t *testing.T) {
+	pkgs := []string{"pkg/a", "pkg/b", "pkg/c", "pkg/d", "pkg/new"}
+	timings := map[string]time.Duration{
+		"pkg/a":       8 * time.Second,
+		"pkg/b":       5 * time.Second,
+		"pkg/c":       4 * time.Second,
+		"pkg/d":       3 * time.Second,
+		"pkg/removed": 10 * time.Second,
+	}
+
+	// pkg/new is estimated at the average of 6s
+	assert.DeepEqual(t,
+		selectPartition(pkgs, timings, partitionValue{index: 1, total: 2}),
+		[]string{"pkg/a", "pkg/c"})
+	assert.DeepEqual(t,
+		selectPartition(pkgs, timings, partitionValue{index: 2, total: 2}),
+		[]string{"pkg/b", "pkg/d", "pkg/new"})
+}
+
+func TestReadPackageTimings(t *testing.T) {
+	jsonfile := fs.NewFile(t, t.Name(), fs.WithContent(`{"Package": "pkg/a", "Test": "TestOne", "Action": "run"}
+{"Package": "pkg/a", "Test": "TestOne/sub", "Action": "run"}
+{"Package": "pkg/a", "Test": "TestOne/sub", "Action": "pass", "Elapsed": 1.5}
+{"Package": "pkg/a", "Test": "TestOne", "Action": "pass", "Elapsed": 2}
+{"Package": "pkg/a", "Test": "TestTwo", "Action": "run"}
+{"Package": "pkg/a", "Test": "TestTwo", "Action": "fail", "Elapsed": 0.5}
+{"Package": "pkg/a", "Action": "fail"}
+{"Package": "pkg/b", "Test": "TestOne", "Action": "run"}
+{"Package": "pkg/b", "Test": "TestOne", "Action": "pass", "Elapsed": 1}
+{"Package": "pkg/b", "Action": "pass"}
+`))
+	defer jsonfile.Remove()
+
+	timings, err := readPackageTimings(jsonfile.Path())
+	assert.NilError(t, err)
+	expected := map[string]time.Duration{
+		"pkg/a": 2500 * time.Millisecond,
+		"pkg/b": time.Second,
+	}
+	assert.DeepEqual(t, timings, expected)
+}
+
+func TestRun_WithPartition(t *testing.T) {
+	reset := patchListPackagesFn(func(patterns []string) []string {
+		return []string{"pkg/a", "pkg/b", "pkg/c"}
+	})
+	defer reset()
+
+	var commands [][]string
+	fn := func(args []string) proc {
+		commands = append(commands, args)
+		return proc{
+			cmd:    fakeWaiter{},
+			stdout: strings.NewReader(""),
+			stderr: strings.NewReader(""),
+		}
+	}
+	defer patchStartGoTestFn(fn)()
+
+	opts := &options{
+		format:      "testname",
+		partition:   &partitionValue{index: 2, total: 2},
+		stdout:      new(bytes.Buffer),
+		stderr:      new(bytes.Buffer),
+		hideSummary: newHideSummaryValue(),
+	}
+	err := run(opts)
+	assert.NilError(t, err)
+
+	pkgs := selectPartition([]string{"pkg/a", "pkg/b", "pkg/c"}, nil, *opts.partition)
+	expected := [][]string{append([]string{"go", "test", "-json"}, pkgs...)}
+	assert.DeepEqual(t, commands, expected)
+}
+
+func TestRun_WithPartition_NoPackages(t *testing.T) {
+	reset := patchListPackagesFn(func(patterns []string) []string {
+		return []string{"pkg/a"}
+	})
+	defer reset()
+
+	fn := func(args []string) proc {
+		t.Fatalf("go test should not be run for a partition with no packages")
+		return proc{}
+	}
+	defer patchStartGoTestFn(fn)()
+
+	dir := fs.NewDir(t, t.Name())
+	defer dir.Remove()
+
+	// pkg/a is assigned to one of the two partitions, use the other one.
+	partition := &partitionValue{index: 1, total: 2}
+	if len(selectPartition([]string{"pkg/a"}, nil, *partition)) > 0 {
+		partition.index = 2
+	}
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		format:                       "testname",
+		partition:                    partition,
+		junitFile:                    dir.Join("junit.xml"),
+		junitTestSuiteNameFormat:     &junitFieldFormatValue{},
+		junitTestCaseClassnameFormat: &junitFieldFormatValue{},
+		junitSubtests:                &junitSubtestsValue{},
+		stdout:                       out,
+		stderr:                       new(bytes.Buffer),
+		hideSummary:                  newHideSummaryValue(),
+	}
+	assert.NilError(t, run(opts))
+	assert.Assert(t, strings.Contains(out.String(), "DONE 0 tests"), out.String())
+
+	_, err := os.Stat(dir.Join("junit.xml"))
+	assert.NilError(t, err, "expected the JUnit file to be written")
+}
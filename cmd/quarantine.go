@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gotest.tools/gotestsum/testjson"
+)
+
+// quarantineList is a list of patterns that match the name of quarantined
+// tests. Each pattern is a package path, followed by a period and the name of a
+// test, ex: example.com/pkg/foo.TestSomething.
+type quarantineList []string
+
+// readQuarantineFile reads a list of patterns from a file. Each line of the
+// file is a pattern. Blank lines, and lines which start with # are ignored.
+func readQuarantineFile(filename string) (quarantineList, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quarantine file: %v", err)
+	}
+	defer fh.Close() // nolint: errcheck
+
+	var patterns quarantineList
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := path.Match(line, ""); err != nil {
+			return nil, fmt.Errorf("invalid quarantine pattern %q: %v", line, err)
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read quarantine file: %v", err)
+	}
+	return patterns, nil
+}
+
+// match returns true if the test case matches any pattern in the list. The
+// package in the pattern may be the full package path, or the package path
+// relative to the module root. Patterns are matched using path.Match.
+//
+// A pattern which matches the name of a root test case also matches all of the
+// subtests of that test case.
+func (q quarantineList) match(tc testjson.TestCase) bool {
+	root, _ := tc.Test.Split()
+	names := []string{
+		tc.Package + "." + tc.Test.Name(),
+		tc.Package + "." + root,
+		testjson.RelativePackagePath(tc.Package) + "." + tc.Test.Name(),
+		testjson.RelativePackagePath(tc.Package) + "." + root,
+	}
+	for _, pattern := range q {
+		for _, name := range names {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// quarantineExitErr returns nil if exitErr was caused only by the failure of
+// quarantined tests, otherwise returns exitErr.
+func quarantineExitErr(exec *testjson.Execution, exitErr error) error {
+	if exitErr == nil || ExitCodeWithDefault(exitErr) != 1 {
+		return exitErr
+	}
+	if len(exec.Failed()) > 0 || len(exec.Errors()) > 0 || len(exec.Quarantined()) == 0 {
+		return exitErr
+	}
+	return nil
+}
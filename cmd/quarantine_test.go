@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestReadQuarantineFile(t *testing.T) {
+	file := fs.NewFile(t, t.Name(), fs.WithContent(`
+# comments and blank lines are ignored
+example.com/pkg/one.TestOne
+
+  example.com/pkg/*.TestTwo
+`))
+	defer file.Remove()
+
+	list, err := readQuarantineFile(file.Path())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, list, quarantineList{"example.com/pkg/one.TestOne", "example.com/pkg/*.TestTwo"})
+}
+
+func TestReadQuarantineFile_InvalidPattern(t *testing.T) {
+	file := fs.NewFile(t, t.Name(), fs.WithContent("example.com/pkg.Test[\n"))
+	defer file.Remove()
+
+	_, err := readQuarantineFile(file.Path())
+	assert.ErrorContains(t, err, `invalid quarantine pattern "example.com/pkg.Test["`)
+}
+
+func TestQuarantineList_Match(t *testing.T) {
+	list := quarantineList{
+		"example.com/pkg/one.TestOne",
+		"example.com/pkg/*.TestTwo",
+		"example.com/pkg/three.TestThree/case_1",
+	}
+	type testCase struct {
+		pkg      string
+		test     testjson.TestName
+		expected bool
+	}
+	var testCases = []testCase{
+		{pkg: "example.com/pkg/one", test: "TestOne", expected: true},
+		{pkg: "example.com/pkg/one", test: "TestOne/subtest", expected: true},
+		{pkg: "example.com/pkg/one", test: "TestOneMore"},
+		{pkg: "example.com/pkg/two", test: "TestTwo", expected: true},
+		{pkg: "example.com/pkg/two/nested", test: "TestTwo"},
+		{pkg: "example.com/pkg/three", test: "TestThree/case_1", expected: true},
+		{pkg: "example.com/pkg/three", test: "TestThree/case_2"},
+		{pkg: "example.com/pkg/three", test: "TestThree"},
+	}
+	for _, tc := range testCases {
+		actual := list.match(testjson.TestCase{Package: tc.pkg, Test: tc.test})
+		assert.Equal(t, actual, tc.expected, "%v.%v", tc.pkg, tc.test)
+	}
+}
+
+func TestRun_WithQuarantinedFailures(t *testing.T) {
+	file := fs.NewFile(t, t.Name(), fs.WithContent("pkg.TestFlaky\n"))
+	defer file.Remove()
+
+	jsonFailed := `{"Package": "pkg", "Test": "TestFlaky", "Action": "run"}
+{"Package": "pkg", "Test": "TestFlaky", "Action": "fail"}
+{"Package": "pkg", "Test": "TestOther", "Action": "run"}
+{"Package": "pkg", "Test": "TestOther", "Action": "pass"}
+{"Package": "pkg", "Action": "fail"}
+`
+	fn := func(args []string) proc {
+		return proc{
+			cmd:    fakeWaiter{result: newExitCode("failed", 1)},
+			stdout: strings.NewReader(jsonFailed),
+			stderr: strings.NewReader(""),
+		}
+	}
+	defer patchStartGoTestFn(fn)()
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		rawCommand:     true,
+		args:           []string{"./test.test"},
+		format:         "testname",
+		quarantineFile: file.Path(),
+		stdout:         out,
+		stderr:         new(bytes.Buffer),
+		hideSummary:    newHideSummaryValue(),
+	}
+	err := run(opts)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(out.String(), "DONE 2 tests, 1 quarantined"), out.String())
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// startAndScanReplay scans the test2json output from opts.replayFile, instead
+// of the output from 'go test'.
+func startAndScanReplay(ctx context.Context, opts *options, cfg testjson.ScanConfig) (waiter, error) {
+	f, err := os.Open(opts.replayFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %v", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Errorf("Failed to close replay file: %v", err)
+		}
+	}()
+
+	cfg.Stdout = f
+	if opts.replaySpeed > 0 {
+		paced := newPacedReader(ctx, f, opts.replaySpeed)
+		defer paced.Close() // nolint: errcheck
+		cfg.Stdout = paced
+	}
+	cfg.Execution.SetRecorded(true)
+	exec, err := testjson.ScanTestOutput(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return doneWaiter{err: FailedExitErr(exec, nil)}, nil
+}
+
+// sleep is a shim for testing
+var sleep = func(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// newPacedReader returns a reader which yields each line from source after
+// waiting for the time between the TestEvent on the line, and the TestEvent on
+// the previous line, divided by speed. The reader must be closed to stop the
+// goroutine which reads from source.
+func newPacedReader(ctx context.Context, source io.Reader, speed float64) io.ReadCloser {
+	ctx, cancel := context.WithCancel(ctx)
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(copyPaced(ctx, writer, source, speed))
+	}()
+	return &pacedReader{PipeReader: reader, cancel: cancel}
+}
+
+type pacedReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (r *pacedReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+func copyPaced(ctx context.Context, out io.Writer, source io.Reader, speed float64) error {
+	var previous time.Time
+	scanner := bufio.NewScanner(source)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line := scanner.Bytes()
+
+		var event struct{ Time time.Time }
+		if err := json.Unmarshal(line, &event); err == nil && !event.Time.IsZero() {
+			if !previous.IsZero() && event.Time.After(previous) {
+				sleep(ctx, time.Duration(float64(event.Time.Sub(previous))/speed))
+			}
+			previous = event.Time
+		}
+
+		if _, err := out.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
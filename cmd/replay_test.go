@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestRun_WithReplay(t *testing.T) {
+	fn := func(args []string) proc {
+		t.Fatalf("go test should not be run when using --replay")
+		return proc{}
+	}
+	reset := patchStartGoTestFn(fn)
+	defer reset()
+
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("events.json", `{"Time": "2018-03-22T22:33:35.000Z", "Package": "pkg", "Action": "run"}
+{"Time": "2018-03-22T22:33:35.000Z", "Package": "pkg", "Test": "TestOne", "Action": "run"}
+{"Time": "2018-03-22T22:33:36.000Z", "Package": "pkg", "Test": "TestOne", "Action": "pass", "Elapsed": 0.1}
+{"Time": "2018-03-22T22:33:36.000Z", "Package": "pkg", "Test": "TestTwo", "Action": "run"}
+{"Time": "2018-03-22T22:33:38.000Z", "Package": "pkg", "Test": "TestTwo", "Action": "fail", "Elapsed": 0.2}
+{"Time": "2018-03-22T22:33:38.500Z", "Package": "pkg", "Action": "fail", "Elapsed": 0.3}
+`))
+	defer dir.Remove()
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		format:      "testname",
+		replayFile:  dir.Join("events.json"),
+		historyFile: dir.Join("history.json"),
+		stdout:      out,
+		stderr:      os.Stderr,
+		hideSummary: newHideSummaryValue(),
+	}
+	err := run(opts)
+	assert.Error(t, err, "1 tests failed")
+	assert.Equal(t, ExitCodeWithDefault(err), 1)
+
+	expected := `PASS pkg.TestOne (0.10s)
+FAIL pkg.TestTwo (0.20s)
+FAIL pkg
+`
+	assert.Assert(t, strings.HasPrefix(out.String(), expected), out.String())
+	assert.Assert(t, strings.Contains(out.String(), "DONE 2 tests, 1 failure in 3.500s"), out.String())
+
+	_, err = os.Stat(dir.Join("history.json"))
+	assert.Assert(t, os.IsNotExist(err), "history file should not be written for a replay")
+}
+
+func TestRun_WithReplay_AllPassed(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("events.json", `{"Package": "pkg", "Test": "TestOne", "Action": "run"}
+{"Package": "pkg", "Test": "TestOne", "Action": "pass", "Elapsed": 0.1}
+{"Package": "pkg", "Action": "pass", "Elapsed": 0.3}
+`))
+	defer dir.Remove()
+
+	opts := &options{
+		format:      "testname",
+		replayFile:  dir.Join("events.json"),
+		stdout:      new(bytes.Buffer),
+		stderr:      os.Stderr,
+		hideSummary: newHideSummaryValue(),
+	}
+	assert.NilError(t, run(opts))
+}
+
+func TestRun_WithReplay_QuarantinedFailures(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("events.json", `{"Package": "pkg", "Test": "TestFlaky", "Action": "run"}
+{"Package": "pkg", "Test": "TestFlaky", "Action": "fail", "Elapsed": 0.1}
+{"Package": "pkg", "Test": "TestOther", "Action": "run"}
+{"Package": "pkg", "Test": "TestOther", "Action": "pass", "Elapsed": 0.1}
+{"Package": "pkg", "Action": "fail", "Elapsed": 0.3}
+`),
+		fs.WithFile("quarantine", "pkg.TestFlaky\n"))
+	defer dir.Remove()
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		format:         "testname",
+		replayFile:     dir.Join("events.json"),
+		quarantineFile: dir.Join("quarantine"),
+		stdout:         out,
+		stderr:         new(bytes.Buffer),
+		hideSummary:    newHideSummaryValue(),
+	}
+	assert.NilError(t, run(opts))
+	assert.Assert(t, strings.Contains(out.String(), "DONE 2 tests, 1 quarantined"), out.String())
+}
+
+func TestOptions_Validate_WithReplay(t *testing.T) {
+	opts := options{replayFile: "events.json", rerunFailsMaxAttempts: 2}
+	assert.Error(t, opts.Validate(), "--replay can not be used with --rerun-fails")
+
+	opts = options{replayFile: "events.json", replaySpeed: -1}
+	assert.Error(t, opts.Validate(), "--replay-speed must not be negative")
+}
+
+func TestNewPacedReader(t *testing.T) {
+	var waits []time.Duration
+	orig := sleep
+	sleep = func(_ context.Context, d time.Duration) {
+		waits = append(waits, d)
+	}
+	defer func() { sleep = orig }()
+
+	source := `{"Time":"2018-03-22T22:33:35.000Z","Action":"run","Package":"pkg","Test":"TestOne"}
+{"Time":"2018-03-22T22:33:37.000Z","Action":"pass","Package":"pkg","Test":"TestOne"}
+not json
+{"Time":"2018-03-22T22:33:37.000Z","Action":"run","Package":"pkg","Test":"TestTwo"}
+{"Time":"2018-03-22T22:33:38.000Z","Action":"pass","Package":"pkg","Test":"TestTwo"}
+`
+	reader := newPacedReader(context.Background(), strings.NewReader(source), 2)
+	raw, err := ioutil.ReadAll(reader)
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), source)
+	assert.DeepEqual(t, waits, []time.Duration{time.Second, 500 * time.Millisecond})
+}
+
+func TestNewPacedReader_Close(t *testing.T) {
+	sleeping, stopped := make(chan struct{}), make(chan struct{})
+	orig := sleep
+	sleep = func(ctx context.Context, _ time.Duration) {
+		close(sleeping)
+		<-ctx.Done()
+		close(stopped)
+	}
+	defer func() { sleep = orig }()
+
+	source := `{"Time":"2018-03-22T22:33:35.000Z","Action":"run","Package":"pkg","Test":"TestOne"}
+{"Time":"2018-03-22T22:33:37.000Z","Action":"pass","Package":"pkg","Test":"TestOne"}
+`
+	reader := newPacedReader(context.Background(), strings.NewReader(source), 1)
+	line := make([]byte, 1024)
+	_, err := reader.Read(line)
+	assert.NilError(t, err)
+	<-sleeping
+	assert.NilError(t, reader.Close())
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("paced reader did not stop after Close")
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// ReportFlags are the flags which select the reports written by WriteReports.
+// They are used by the tool commands which write reports from the test2json
+// output of an earlier run.
+type ReportFlags struct {
+	opts *options
+}
+
+// SetupReportFlags adds the flags used to select the reports to flags.
+func SetupReportFlags(flags *pflag.FlagSet) *ReportFlags {
+	opts := &options{
+		junitTestCaseClassnameFormat: &junitFieldFormatValue{},
+		junitTestSuiteNameFormat:     &junitFieldFormatValue{},
+		junitSubtests:                &junitSubtestsValue{},
+	}
+	flags.StringVar(&opts.junitFile, "junitfile", "",
+		"write a JUnit XML file")
+	flags.Var(opts.junitTestSuiteNameFormat, "junitfile-testsuite-name",
+		"format the testsuite name field as: "+junitFieldFormatValues)
+	flags.Var(opts.junitTestCaseClassnameFormat, "junitfile-testcase-classname",
+		"format the testcase classname field as: "+junitFieldFormatValues)
+	flags.BoolVar(&opts.junitSystemOut, "junitfile-system-out", false,
+		"include the output of every testcase in a system-out element")
+	flags.BoolVar(&opts.junitExtended, "junitfile-extended", false,
+		"add errors, skipped, timestamp, and hostname attributes, and report build failures and TestMain as errors")
+	flags.BoolVar(&opts.junitGroupReruns, "junitfile-group-reruns", false,
+		"report every attempt of a re-run test as a single testcase, with flakyFailure or rerunFailure elements")
+	flags.Var(opts.junitSubtests, "junitfile-subtests",
+		"format subtests as: "+junitSubtestsValues)
+	flags.StringVar(&opts.tapFile, "tapfile", "",
+		"write a TAP version 13 file")
+	flags.StringVar(&opts.htmlFile, "htmlfile", "",
+		"write an HTML report file")
+	flags.StringVar(&opts.markdownFile, "markdownfile", "",
+		"write a markdown report file, for use in a pull request comment")
+	flags.StringVar(&opts.summaryJSONFile, "summary-json", "",
+		"write a JSON summary of the test run to file")
+	flags.StringVar(&opts.benchJSONFile, "bench-json", "",
+		"write the benchmark results to a JSON file")
+	flags.StringVar(&opts.quarantineFile, "quarantine", "",
+		"path to a file with a list of tests which may fail without failing the run")
+	return &ReportFlags{opts: opts}
+}
+
+// NewExecution returns an Execution for events which were recorded by an
+// earlier run. The Execution uses the quarantine file from the flags, and keeps
+// the output that is needed by the reports.
+func (f *ReportFlags) NewExecution() (*testjson.Execution, error) {
+	exec := testjson.NewExecution()
+	if f.opts.quarantineFile != "" {
+		quarantine, err := readQuarantineFile(f.opts.quarantineFile)
+		if err != nil {
+			return nil, err
+		}
+		exec.SetQuarantine(quarantine.match)
+	}
+	exec.SetKeepPassedOutput(f.opts.junitFile != "" && f.opts.junitSystemOut)
+	exec.SetRecorded(true)
+	return exec, nil
+}
+
+// WriteReports writes every report selected by the flags.
+func (f *ReportFlags) WriteReports(exec *testjson.Execution) error {
+	opts := f.opts
+	if err := writeJUnitFile(opts, exec); err != nil {
+		return err
+	}
+	if err := writeTAPFile(opts, exec); err != nil {
+		return err
+	}
+	if err := writeHTMLFile(opts, exec); err != nil {
+		return err
+	}
+	if err := writeMarkdownFile(opts, exec); err != nil {
+		return err
+	}
+	if err := writeSummaryJSONFile(opts, exec); err != nil {
+		return err
+	}
+	return writeBenchJSONFile(opts, exec)
+}
+
+// FailedExitErr returns an error with exit code 1 if any test failed, and did
+// not pass when it was re-run, or if there were any errors. A test passed when
+// it was re-run if a pass for the same test was read after the failure, from the
+// same input. sameInput reports whether two test cases were read from the same
+// input. When sameInput is nil every test case is from the same input.
+func FailedExitErr(exec *testjson.Execution, sameInput func(a, b testjson.TestCase) bool) error {
+	failed := 0
+	for _, tc := range exec.Failed() {
+		if !passedOnRerun(exec.Package(tc.Package), tc, sameInput) {
+			failed++
+		}
+	}
+	switch {
+	case failed > 0:
+		return &failedError{msg: fmt.Sprintf("%d tests failed", failed)}
+	case len(exec.Errors()) > 0:
+		return &failedError{msg: fmt.Sprintf("%d errors", len(exec.Errors()))}
+	}
+	return nil
+}
+
+// passedOnRerun returns true if the test has a pass which was read after tc.
+// Test case IDs increase in the order the events were read, so a later pass has
+// a larger ID.
+func passedOnRerun(
+	pkg *testjson.Package,
+	tc testjson.TestCase,
+	sameInput func(a, b testjson.TestCase) bool,
+) bool {
+	for _, passed := range pkg.Passed {
+		if passed.Test != tc.Test || passed.ID < tc.ID {
+			continue
+		}
+		if sameInput == nil || sameInput(tc, passed) {
+			return true
+		}
+	}
+	return false
+}
@@ -48,12 +48,17 @@ func rerunFailed(ctx context.Context, opts *options, scanConfig testjson.ScanCon
 
 	rec := newFailureRecorderFromExecution(scanConfig.Execution)
 	for attempts := 0; rec.count() > 0 && attempts < opts.rerunFailsMaxAttempts; attempts++ {
+		clearFooter(scanConfig.Handler)
 		testjson.PrintSummary(opts.stdout, scanConfig.Execution, testjson.SummarizeNone)
 		opts.stdout.Write([]byte("\n")) // nolint: errcheck
 
 		nextRec := newFailureRecorder(scanConfig.Handler)
 		for _, tc := range tcFilter(rec.failures) {
-			goTestProc, err := startGoTestFn(ctx, goTestCmdArgs(opts, newRerunOptsFromTestCase(tc)))
+			rerunProcOpts, coverProfile := opts, coverProfileFile(opts)
+			if coverProfile != "" {
+				rerunProcOpts = withCoverProfile(opts, fmt.Sprintf("%s.rerun%d", coverProfile, attempts+1))
+			}
+			goTestProc, err := startGoTestFn(ctx, opts.goTestProcs, goTestCmdArgs(rerunProcOpts, newRerunOptsFromTestCase(tc)))
 			if err != nil {
 				return err
 			}
@@ -72,6 +77,12 @@ func rerunFailed(ctx context.Context, opts *options, scanConfig testjson.ScanCon
 			if exitErr != nil {
 				nextRec.lastErr = exitErr
 			}
+			if coverProfile != "" {
+				filename := coverProfileFile(rerunProcOpts)
+				if err := mergeCoverProfiles(coverProfile, []string{filename}); err != nil {
+					return err
+				}
+			}
 			if err := hasErrors(exitErr, scanConfig.Execution); err != nil {
 				return err
 			}
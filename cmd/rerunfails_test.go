@@ -135,9 +135,37 @@ func TestRerunFailed_ReturnsAnErrorWhenTheLastTestIsSuccessful(t *testing.T) {
 	assert.Error(t, err, "run-failed-3")
 }
 
+func TestRerunFailed_ClearsFooterBeforeSummary(t *testing.T) {
+	fn := func(args []string) proc {
+		return proc{
+			cmd: fakeWaiter{},
+			stdout: strings.NewReader(`{"Package": "pkg", "Test": "TestOne", "Action": "run"}
+{"Package": "pkg", "Test": "TestOne", "Action": "pass"}
+{"Package": "pkg", "Action": "pass"}
+`),
+			stderr: bytes.NewReader(nil),
+		}
+	}
+	defer patchStartGoTestFn(fn)()
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		rerunFailsMaxInitialFailures: 10,
+		rerunFailsMaxAttempts:        1,
+		stdout:                       out,
+	}
+	cfg := testjson.ScanConfig{
+		Execution: newExecutionWithTwoFailures(t),
+		Handler:   &eventHandler{formatter: footerFormatter{out: out}},
+	}
+	assert.NilError(t, rerunFailed(context.Background(), opts, cfg))
+	assert.Assert(t, strings.HasPrefix(out.String(), "clear footer\n\nDONE 2 tests, 2 failures"),
+		out.String())
+}
+
 func patchStartGoTestFn(f func(args []string) proc) func() {
 	orig := startGoTestFn
-	startGoTestFn = func(ctx context.Context, args []string) (proc, error) {
+	startGoTestFn = func(ctx context.Context, _ *goTestProcs, args []string) (proc, error) {
 		return f(args), nil
 	}
 	return func() {
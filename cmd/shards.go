@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// startAndScan runs 'go test' and scans the output into cfg.Execution. When
+// opts.shards is greater than 1 the list of packages is split into groups, and
+// each group is run by a separate 'go test' process.
+func startAndScan(ctx context.Context, opts *options, cfg testjson.ScanConfig) (waiter, error) {
+	switch {
+	case opts.replayFile != "":
+		return startAndScanReplay(ctx, opts, cfg)
+	case opts.shards > 1:
+		return startAndScanShards(ctx, opts, cfg)
+	}
+	goTestProc, err := startGoTestFn(ctx, opts.goTestProcs, goTestCmdArgs(opts, rerunOpts{}))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Stdout = goTestProc.stdout
+	cfg.Stderr = goTestProc.stderr
+	_, err = testjson.ScanTestOutput(cfg)
+	return goTestProc.cmd, err
+}
+
+func startAndScanShards(ctx context.Context, opts *options, cfg testjson.ScanConfig) (waiter, error) {
+	pkgs, err := listPackagesFn(ctx, cmdArgPackageList(opts, rerunOpts{}, "./..."))
+	if err != nil {
+		return nil, err
+	}
+
+	// cancel stops the processes which were already started when a later
+	// shard fails to start.
+	ctx, cancel := context.WithCancel(ctx)
+	var group errgroup.Group
+	var procs shardWaiter
+	var coverProfiles []string
+	coverProfile := coverProfileFile(opts)
+	for i, shard := range splitPackages(pkgs, opts.shards) {
+		shardOpts := opts
+		if coverProfile != "" {
+			filename := fmt.Sprintf("%s.shard%d", coverProfile, i)
+			coverProfiles = append(coverProfiles, filename)
+			shardOpts = withCoverProfile(opts, filename)
+		}
+		shardOpts = shardOptsWithPackages(shardOpts, shard)
+		goTestProc, err := startGoTestFn(ctx, opts.goTestProcs, goTestCmdArgs(shardOpts, rerunOpts{}))
+		if err != nil {
+			cancel()
+			// Wait for the scans to finish reading from the stopped processes,
+			// so that they do not write to the Execution after this returns.
+			_ = group.Wait()
+			_ = procs.Wait()
+			return nil, err
+		}
+		procs = append(procs, goTestProc.cmd)
+
+		shardCfg := cfg
+		shardCfg.Stdout = goTestProc.stdout
+		shardCfg.Stderr = goTestProc.stderr
+		group.Go(func() error {
+			_, err := testjson.ScanTestOutput(shardCfg)
+			return err
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return &cancelWaiter{cancel: cancel, wrapped: procs}, err
+	}
+	if coverProfile != "" {
+		// Wait for every process to exit, so that the coverage profiles are
+		// written before they are merged.
+		err := procs.Wait()
+		cancel()
+		if mergeErr := replaceCoverProfile(coverProfile, coverProfiles); mergeErr != nil {
+			return nil, mergeErr
+		}
+		return doneWaiter{err: err}, nil
+	}
+	return &cancelWaiter{cancel: cancel, wrapped: procs}, nil
+}
+
+func shardOptsWithPackages(opts *options, pkgs []string) *options {
+	result := *opts
+	result.packages = pkgs
+	return &result
+}
+
+// doneWaiter is a waiter for processes which have already exited.
+type doneWaiter struct {
+	err error
+}
+
+func (w doneWaiter) Wait() error {
+	return w.err
+}
+
+// splitPackages into at most n groups. Packages are assigned to groups in
+// order, so that packages which are next to each other in the list, and
+// likely to have a similar size, are spread across all the groups.
+func splitPackages(pkgs []string, n int) [][]string {
+	if len(pkgs) < n {
+		n = len(pkgs)
+	}
+	groups := make([][]string, n)
+	for i, pkg := range pkgs {
+		groups[i%n] = append(groups[i%n], pkg)
+	}
+	return groups
+}
+
+// listPackagesFn is a shim for testing
+var listPackagesFn = listPackages
+
+// listPackages returns the import path of every package matched by patterns.
+func listPackages(ctx context.Context, patterns []string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "go", append([]string{"list"}, patterns...)...)
+	cmd.Stderr = os.Stderr
+	log.Debugf("exec: %s", cmd.Args)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list packages")
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// shardWaiter waits for every 'go test' process started for a shard.
+type shardWaiter []waiter
+
+// Wait for all the processes to exit. If more than one process exits with an
+// error, the error with the highest exit code is returned.
+func (w shardWaiter) Wait() error {
+	var result error
+	for _, proc := range w {
+		err := proc.Wait()
+		if err != nil && ExitCodeWithDefault(err) > ExitCodeWithDefault(result) {
+			result = err
+		}
+	}
+	return result
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+)
+
+func TestSplitPackages(t *testing.T) {
+	type testCase struct {
+		name     string
+		pkgs     []string
+		n        int
+		expected [][]string
+	}
+	fn := func(t *testing.T, tc testCase) {
+		assert.DeepEqual(t, splitPackages(tc.pkgs, tc.n), tc.expected)
+	}
+	var testCases = []testCase{
+		{
+			name:     "more packages than shards",
+			pkgs:     []string{"a", "b", "c", "d", "e"},
+			n:        2,
+			expected: [][]string{{"a", "c", "e"}, {"b", "d"}},
+		},
+		{
+			name:     "fewer packages than shards",
+			pkgs:     []string{"a", "b"},
+			n:        4,
+			expected: [][]string{{"a"}, {"b"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fn(t, tc)
+		})
+	}
+}
+
+func TestRun_WithShards(t *testing.T) {
+	pkgs := []string{"pkg/one", "pkg/two", "pkg/three"}
+	reset := patchListPackagesFn(func(patterns []string) []string {
+		assert.DeepEqual(t, patterns, []string{"./..."})
+		return pkgs
+	})
+	defer reset()
+
+	var commands [][]string
+	fn := func(args []string) proc {
+		commands = append(commands, args)
+
+		stdout := new(bytes.Buffer)
+		for _, pkg := range args[3:] {
+			fmt.Fprintf(stdout, `{"Package": "%[1]s", "Test": "TestOne", "Action": "run"}
+{"Package": "%[1]s", "Test": "TestOne", "Action": "pass"}
+{"Package": "%[1]s", "Action": "pass"}
+`, pkg)
+		}
+		return proc{
+			cmd:    fakeWaiter{},
+			stdout: stdout,
+			stderr: strings.NewReader(""),
+		}
+	}
+	defer patchStartGoTestFn(fn)()
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		format:      "testname",
+		shards:      2,
+		stdout:      out,
+		stderr:      new(bytes.Buffer),
+		hideSummary: newHideSummaryValue(),
+	}
+	err := run(opts)
+	assert.NilError(t, err)
+
+	expected := [][]string{
+		{"go", "test", "-json", "pkg/one", "pkg/three"},
+		{"go", "test", "-json", "pkg/two"},
+	}
+	assert.DeepEqual(t, commands, expected)
+	assert.Assert(t, strings.Contains(out.String(), "DONE 3 tests"), out.String())
+}
+
+func TestStartAndScanShards_StartFails(t *testing.T) {
+	reset := patchListPackagesFn(func(patterns []string) []string {
+		return []string{"pkg/one", "pkg/two"}
+	})
+	defer reset()
+
+	waited := make(chan struct{})
+	orig := startGoTestFn
+	defer func() { startGoTestFn = orig }()
+	startGoTestFn = func(ctx context.Context, _ *goTestProcs, args []string) (proc, error) {
+		if args[len(args)-1] == "pkg/two" {
+			return proc{}, fmt.Errorf("failed to start")
+		}
+		// the output of the first shard ends when the context is cancelled
+		stdout, writer := io.Pipe()
+		go func() {
+			<-ctx.Done()
+			writer.Close() // nolint: errcheck
+		}()
+		return proc{
+			cmd:    waiterFunc(func() error { close(waited); return nil }),
+			stdout: stdout,
+			stderr: strings.NewReader(""),
+		}, nil
+	}
+
+	opts := &options{shards: 2}
+	cfg := testjson.ScanConfig{Execution: testjson.NewExecution()}
+	_, err := startAndScanShards(context.Background(), opts, cfg)
+	assert.Error(t, err, "failed to start")
+	select {
+	case <-waited:
+	default:
+		t.Fatal("expected the first shard to be waited on")
+	}
+}
+
+type waiterFunc func() error
+
+func (f waiterFunc) Wait() error {
+	return f()
+}
+
+func TestShardWaiter_Wait(t *testing.T) {
+	w := shardWaiter{
+		fakeWaiter{},
+		fakeWaiter{result: newExitCode("failed", 1)},
+		fakeWaiter{result: newExitCode("build failed", 2)},
+		fakeWaiter{result: newExitCode("failed again", 1)},
+	}
+	assert.Error(t, w.Wait(), "build failed")
+}
+
+func patchListPackagesFn(f func(patterns []string) []string) func() {
+	orig := listPackagesFn
+	listPackagesFn = func(ctx context.Context, patterns []string) ([]string, error) {
+		return f(patterns), nil
+	}
+	return func() {
+		listPackagesFn = orig
+	}
+}
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// quitSignal is sent to the test binaries when tests stall, so that the Go
+// runtime prints a goroutine dump.
+var quitSignal os.Signal = syscall.SIGQUIT
+
+// maxStallOutputLines is the number of lines of output printed for each
+// stalled test. Earlier lines are omitted.
+const maxStallOutputLines = 20
+
+// stallWatchdog is an EventHandler which tracks the tests which are running,
+// and reports the tests which have been running for longer than timeout.
+type stallWatchdog struct {
+	testjson.EventHandler
+	timeout time.Duration
+	out     io.Writer
+	// quit is called the first time tests stall. May be nil.
+	quit func() error
+
+	lock     sync.Mutex
+	running  map[stallKey]*runningTest
+	quitSent bool
+}
+
+type stallKey struct {
+	pkg  string
+	test string
+}
+
+type runningTest struct {
+	started time.Time
+	// paused is true when a parallel test is waiting for other tests to finish.
+	paused   bool
+	output   []string
+	reported bool
+}
+
+func newStallWatchdog(opts *options, handler testjson.EventHandler) *stallWatchdog {
+	w := &stallWatchdog{
+		EventHandler: handler,
+		timeout:      opts.testStallTimeout,
+		out:          opts.stderr,
+		running:      make(map[stallKey]*runningTest),
+	}
+	if opts.goTestProcs != nil {
+		w.quit = func() error {
+			return opts.goTestProcs.signal(quitSignal)
+		}
+	}
+	return w
+}
+
+func (w *stallWatchdog) Event(event testjson.TestEvent, execution *testjson.Execution) error {
+	w.lock.Lock()
+	w.update(event, time.Now())
+	w.lock.Unlock()
+	return w.EventHandler.Event(event, execution)
+}
+
+func (w *stallWatchdog) update(event testjson.TestEvent, now time.Time) {
+	if event.PackageEvent() {
+		if event.Action == testjson.ActionPass || event.Action == testjson.ActionFail {
+			for key := range w.running {
+				if key.pkg == event.Package {
+					delete(w.running, key)
+				}
+			}
+		}
+		return
+	}
+
+	key := stallKey{pkg: event.Package, test: event.Test}
+	if event.Action == testjson.ActionRun {
+		w.running[key] = &runningTest{started: now}
+		return
+	}
+	test, ok := w.running[key]
+	if !ok {
+		return
+	}
+	switch event.Action {
+	case testjson.ActionPause:
+		test.paused = true
+	case testjson.ActionCont:
+		test.paused = false
+		test.started = now
+	case testjson.ActionOutput, testjson.ActionBench:
+		test.output = append(test.output, event.Output)
+	case testjson.ActionPass, testjson.ActionFail, testjson.ActionSkip:
+		delete(w.running, key)
+	}
+}
+
+// watch checks for stalled tests until ctx is done.
+func (w *stallWatchdog) watch(ctx context.Context) {
+	interval := w.timeout / 4
+	if interval > time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.check(now)
+		}
+	}
+}
+
+// check prints the tests which have been running for longer than the timeout
+// at now. Each test is only printed once. A test with a running subtest is not
+// printed, because it is waiting for the subtest.
+func (w *stallWatchdog) check(now time.Time) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var stalled []stallKey
+	for key, test := range w.running {
+		if test.paused || test.reported || now.Sub(test.started) < w.timeout {
+			continue
+		}
+		if w.hasRunningSubTest(key) {
+			continue
+		}
+		stalled = append(stalled, key)
+	}
+	if len(stalled) == 0 {
+		return
+	}
+	sort.Slice(stalled, func(i, j int) bool {
+		a, b := w.running[stalled[i]], w.running[stalled[j]]
+		if !a.started.Equal(b.started) {
+			return a.started.Before(b.started)
+		}
+		return stalled[i].pkg+stalled[i].test < stalled[j].pkg+stalled[j].test
+	})
+
+	// Events are blocked by the lock, so the footer is not printed again
+	// until the report is written.
+	clearFooter(w.EventHandler)
+	fmt.Fprintf(w.out, "\n=== Tests running for more than %v\n", w.timeout)
+	for _, key := range stalled {
+		test := w.running[key]
+		test.reported = true
+		fmt.Fprintf(w.out, "=== STALL: %s %s (%s)\n",
+			testjson.RelativePackagePath(key.pkg), key.test,
+			testjson.FormatDurationAsSeconds(now.Sub(test.started), 1))
+		writeStallOutput(w.out, test.output)
+	}
+
+	if w.quit == nil || w.quitSent {
+		return
+	}
+	w.quitSent = true
+	fmt.Fprintln(w.out, "Sending SIGQUIT to 'go test' to print a goroutine dump")
+	if err := w.quit(); err != nil {
+		log.Errorf("Failed to send SIGQUIT to 'go test': %v", err)
+	}
+}
+
+func (w *stallWatchdog) clearFooter() error {
+	if clearer, ok := w.EventHandler.(footerClearer); ok {
+		return clearer.clearFooter()
+	}
+	return nil
+}
+
+func (w *stallWatchdog) hasRunningSubTest(key stallKey) bool {
+	for other, test := range w.running {
+		if other.pkg == key.pkg && !test.paused && strings.HasPrefix(other.test, key.test+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func writeStallOutput(out io.Writer, output []string) {
+	if omitted := len(output) - maxStallOutputLines; omitted > 0 {
+		fmt.Fprintf(out, "    ... %d lines of output omitted\n", omitted)
+		output = output[omitted:]
+	}
+	for _, line := range output {
+		fmt.Fprint(out, line)
+	}
+}
+
+// goTestProcs is the set of 'go test' processes which are running in their
+// own process group. Used to send a signal to the test binaries started by
+// each 'go test' process.
+type goTestProcs struct {
+	lock sync.Mutex
+	pids map[int]struct{}
+}
+
+func newGoTestProcs() *goTestProcs {
+	return &goTestProcs{pids: make(map[int]struct{})}
+}
+
+func (p *goTestProcs) add(pid int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.pids[pid] = struct{}{}
+}
+
+func (p *goTestProcs) remove(pid int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.pids, pid)
+}
+
+// signal sends sig to the process group of every running 'go test' process.
+func (p *goTestProcs) signal(sig os.Signal) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for pid := range p.pids {
+		if err := signalProcessGroup(pid, sig); err != nil {
+			return fmt.Errorf("failed to signal process group %d: %v", pid, err)
+		}
+	}
+	return nil
+}
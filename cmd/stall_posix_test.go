@@ -0,0 +1,34 @@
+// +build !windows
+
+package cmd
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestGoTestProcs_Signal(t *testing.T) {
+	procs := newGoTestProcs()
+	p, err := startGoTest(context.Background(), procs, []string{"sh", "-c", "sleep 30; exit 0"})
+	assert.NilError(t, err)
+
+	assert.NilError(t, procs.signal(syscall.SIGTERM))
+	done := make(chan error, 1)
+	go func() {
+		done <- p.cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		assert.ErrorContains(t, err, "signal: terminated")
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for process to exit")
+	}
+
+	procs.lock.Lock()
+	defer procs.lock.Unlock()
+	assert.Equal(t, len(procs.pids), 0)
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+)
+
+func TestStallWatchdog_Check(t *testing.T) {
+	out := new(bytes.Buffer)
+	var quitCount int
+	w := newStallWatchdog(&options{testStallTimeout: time.Minute, stderr: out}, noopHandler{})
+	w.quit = func() error {
+		quitCount++
+		return nil
+	}
+
+	start := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	events := []struct {
+		offset time.Duration
+		event  testjson.TestEvent
+	}{
+		{0, testjson.TestEvent{Package: "example.com/pkg", Test: "TestHang", Action: "run"}},
+		{0, testjson.TestEvent{Package: "example.com/pkg", Test: "TestHang", Action: "output", Output: "=== RUN   TestHang\n"}},
+		{0, testjson.TestEvent{Package: "example.com/pkg", Test: "TestHang/sub", Action: "run"}},
+		{0, testjson.TestEvent{Package: "example.com/pkg", Test: "TestHang/sub", Action: "output", Output: "=== RUN   TestHang/sub\n"}},
+		{0, testjson.TestEvent{Package: "example.com/pkg", Test: "TestHang/sub", Action: "output", Output: "    hang_test.go:10: waiting\n"}},
+		{time.Second, testjson.TestEvent{Package: "example.com/pkg", Test: "TestParallel", Action: "run"}},
+		{time.Second, testjson.TestEvent{Package: "example.com/pkg", Test: "TestParallel", Action: "pause"}},
+		{time.Second, testjson.TestEvent{Package: "example.com/pkg", Test: "TestPass", Action: "run"}},
+		{2 * time.Second, testjson.TestEvent{Package: "example.com/pkg", Test: "TestPass", Action: "pass"}},
+		{5 * time.Second, testjson.TestEvent{Package: "example.com/other", Test: "TestSlow", Action: "run"}},
+		{5 * time.Second, testjson.TestEvent{Package: "example.com/done", Test: "TestPanic", Action: "run"}},
+		{5 * time.Second, testjson.TestEvent{Package: "example.com/done", Action: "fail"}},
+	}
+	for _, e := range events {
+		w.update(e.event, start.Add(e.offset))
+	}
+
+	w.check(start.Add(59 * time.Second))
+	assert.Equal(t, out.String(), "")
+
+	w.check(start.Add(65 * time.Second))
+	expected := `
+=== Tests running for more than 1m0s
+=== STALL: example.com/pkg TestHang/sub (65.0s)
+=== RUN   TestHang/sub
+    hang_test.go:10: waiting
+=== STALL: example.com/other TestSlow (60.0s)
+Sending SIGQUIT to 'go test' to print a goroutine dump
+`
+	assert.Equal(t, out.String(), expected)
+	assert.Equal(t, quitCount, 1)
+
+	// tests are only reported once, and quit is only called once
+	out.Reset()
+	w.update(testjson.TestEvent{Package: "example.com/pkg", Test: "TestParallel", Action: "cont"},
+		start.Add(70*time.Second))
+	w.check(start.Add(135 * time.Second))
+	expected = `
+=== Tests running for more than 1m0s
+=== STALL: example.com/pkg TestParallel (65.0s)
+`
+	assert.Equal(t, out.String(), expected)
+	assert.Equal(t, quitCount, 1)
+}
+
+func TestStallWatchdog_Check_ClearsFooter(t *testing.T) {
+	out := new(bytes.Buffer)
+	handler := &eventHandler{formatter: footerFormatter{out: out}}
+	w := newStallWatchdog(&options{testStallTimeout: time.Minute, stderr: out}, handler)
+
+	start := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	w.update(testjson.TestEvent{Package: "example.com/pkg", Test: "TestSlow", Action: "run"}, start)
+	w.check(start.Add(65 * time.Second))
+	expected := `clear footer
+
+=== Tests running for more than 1m0s
+=== STALL: example.com/pkg TestSlow (65.0s)
+`
+	assert.Equal(t, out.String(), expected)
+}
+
+func TestWriteStallOutput_OmitsEarlyLines(t *testing.T) {
+	var output []string
+	for i := 0; i < maxStallOutputLines+3; i++ {
+		output = append(output, fmt.Sprintf("line %d\n", i))
+	}
+	out := new(bytes.Buffer)
+	writeStallOutput(out, output)
+
+	lines := bytes.Split(bytes.TrimRight(out.Bytes(), "\n"), []byte("\n"))
+	assert.Equal(t, len(lines), maxStallOutputLines+1)
+	assert.Equal(t, string(lines[0]), "    ... 3 lines of output omitted")
+	assert.Equal(t, string(lines[1]), "line 3")
+}
+
+func TestOptions_Validate_TestStall(t *testing.T) {
+	opts := &options{testStallQuit: true}
+	assert.Error(t, opts.Validate(), "--test-stall-sigquit requires --test-stall-timeout")
+
+	opts = &options{testStallTimeout: -time.Second}
+	assert.Error(t, opts.Validate(), "--test-stall-timeout must not be negative")
+}
@@ -0,0 +1,17 @@
+// +build windows
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+const processGroupSupported = false
+
+func setProcessGroup(_ *exec.Cmd) {}
+
+func signalProcessGroup(_ int, _ os.Signal) error {
+	return fmt.Errorf("sending a signal to a process group is not supported on windows")
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"gotest.tools/gotestsum/internal/markdown"
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// stepSummaryMaxSize is the maximum size of a GitHub Actions job summary.
+const stepSummaryMaxSize = 1024 * 1024
+
+// writeGitHubStepSummary appends a markdown summary of the execution to the
+// file named by GITHUB_STEP_SUMMARY, when the github-actions format is used.
+func writeGitHubStepSummary(opts *options, execution *testjson.Execution) error {
+	filename := os.Getenv("GITHUB_STEP_SUMMARY")
+	if opts.format != "github-actions" || filename == "" {
+		return nil
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open GitHub step summary file: %v", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Errorf("Failed to close GitHub step summary file: %v", err)
+		}
+	}()
+	return markdown.Write(f, execution, markdown.Config{
+		MaxSize:  stepSummaryMaxSize,
+		Packages: true,
+	})
+}
@@ -1,11 +1,14 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
+
+	"gotest.tools/gotestsum/summaryjson"
 )
 
 func main() {
@@ -26,6 +29,16 @@ func run() error {
 		}
 	}
 
+	var report summaryjson.Report
+	if err := json.NewDecoder(os.Stdin).Decode(&report); err != nil {
+		return fmt.Errorf("failed to read summary from stdin: %v", err)
+	}
+	fmt.Printf("stdin: version=%d packages=%d failed=%d skipped=%d\n",
+		report.Version, len(report.Packages), len(report.Failed), len(report.Skipped))
+	for _, tc := range report.Failed {
+		fmt.Printf("stdin: FAIL %s.%s\n", tc.Package, tc.Test)
+	}
+
 	err := os.Getenv("TEST_STUB_ERROR")
 	if err != "" {
 		return errors.New(err)
@@ -0,0 +1,275 @@
+package benchcmp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"gotest.tools/gotestsum/internal/benchjson"
+	"gotest.tools/gotestsum/log"
+)
+
+// Run the command
+func Run(name string, args []string) error {
+	flags, opts := setupFlags(name)
+	switch err := flags.Parse(args); {
+	case err == pflag.ErrHelp:
+		return nil
+	case err != nil:
+		usage(os.Stderr, name, flags)
+		return err
+	}
+	if flags.NArg() != 2 {
+		usage(os.Stderr, name, flags)
+		return fmt.Errorf("expected 2 arguments, OLD and NEW, got %d", flags.NArg())
+	}
+	opts.old, opts.new = flags.Arg(0), flags.Arg(1)
+	return run(opts, os.Stdout)
+}
+
+const defaultAlpha = 0.05
+
+func setupFlags(name string) (*pflag.FlagSet, *options) {
+	opts := &options{}
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	flags.Usage = func() {
+		usage(os.Stdout, name, flags)
+	}
+	flags.StringVar(&opts.format, "format", "text",
+		"output format, one of: text, json")
+	flags.Float64Var(&opts.alpha, "alpha", defaultAlpha,
+		"a change is significant when the p-value is less than alpha")
+	flags.BoolVar(&opts.debug, "debug", false,
+		"enable debug logging.")
+	return flags, opts
+}
+
+func usage(out io.Writer, name string, flags *pflag.FlagSet) {
+	fmt.Fprintf(out, `Usage:
+    %[1]s [flags] OLD NEW
+
+Read two benchmark json files, OLD and NEW, and print the change in every
+metric of every benchmark which is in both files. The json files are created
+with 'gotestsum --bench-json'.
+
+Run the benchmarks more than once, using 'go test -count', to compare more than
+one sample of each benchmark. The change is the difference between the median
+of the samples. The p-value of the change is calculated with the Mann-Whitney
+U test. A change which is not significant is printed as ~.
+
+Flags:
+`, name)
+	flags.SetOutput(out)
+	flags.PrintDefaults()
+}
+
+type options struct {
+	old    string
+	new    string
+	format string
+	alpha  float64
+	debug  bool
+}
+
+func run(opts *options, out io.Writer) error {
+	if opts.debug {
+		log.SetLevel(log.DebugLevel)
+	}
+	write, ok := writers[opts.format]
+	if !ok {
+		return fmt.Errorf("invalid --format value: %v", opts.format)
+	}
+	if opts.alpha <= 0 || opts.alpha >= 1 {
+		return fmt.Errorf("--alpha must be between 0 and 1")
+	}
+
+	oldReport, err := readBenchJSONFile(opts.old)
+	if err != nil {
+		return err
+	}
+	newReport, err := readBenchJSONFile(opts.new)
+	if err != nil {
+		return err
+	}
+	return write(out, compare(oldReport, newReport, opts.alpha))
+}
+
+func readBenchJSONFile(filename string) (benchjson.Report, error) {
+	in, err := os.Open(filename)
+	if err != nil {
+		return benchjson.Report{}, fmt.Errorf("failed to read benchmark json file: %v", err)
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			log.Errorf("Failed to close file %v: %v", filename, err)
+		}
+	}()
+	report, err := benchjson.Read(in)
+	if err != nil {
+		return report, fmt.Errorf("%v: %v", filename, err)
+	}
+	return report, nil
+}
+
+type sampleKey struct {
+	pkg  string
+	name string
+	unit string
+}
+
+// samples is every value of every metric from a single benchmark json file.
+type samples map[sampleKey][]float64
+
+func samplesFromReport(report benchjson.Report) samples {
+	s := make(samples)
+	for _, b := range report.Benchmarks {
+		for unit, value := range b.Metrics {
+			key := sampleKey{pkg: b.Package, name: b.FullName(), unit: unit}
+			s[key] = append(s[key], value)
+		}
+	}
+	return s
+}
+
+// comparison is the change in a single metric of a benchmark.
+type comparison struct {
+	Package string `json:"package"`
+	// Benchmark is the name of the benchmark, including the GOMAXPROCS suffix.
+	Benchmark string `json:"benchmark"`
+	Unit      string `json:"unit"`
+	// Old is the median of the samples in the OLD file.
+	Old float64 `json:"old"`
+	// New is the median of the samples in the NEW file.
+	New      float64 `json:"new"`
+	OldCount int     `json:"oldCount"`
+	NewCount int     `json:"newCount"`
+	// Delta is the change from Old to New as a percentage of Old. Delta is 0
+	// when Old is 0.
+	Delta float64 `json:"delta"`
+	// P is the p-value of the Mann-Whitney U test of the samples.
+	P float64 `json:"p"`
+	// Significant is true when P is less than alpha.
+	Significant bool `json:"significant"`
+}
+
+// compare returns the change in every metric of every benchmark which is in
+// both reports, sorted by unit, then by package and benchmark name.
+func compare(oldReport, newReport benchjson.Report, alpha float64) []comparison {
+	oldSamples, newSamples := samplesFromReport(oldReport), samplesFromReport(newReport)
+	result := []comparison{}
+	for key, oldValues := range oldSamples {
+		newValues, ok := newSamples[key]
+		if !ok {
+			continue
+		}
+		c := comparison{
+			Package:   key.pkg,
+			Benchmark: key.name,
+			Unit:      key.unit,
+			Old:       median(oldValues),
+			New:       median(newValues),
+			OldCount:  len(oldValues),
+			NewCount:  len(newValues),
+			P:         mannWhitneyUTest(oldValues, newValues),
+		}
+		if c.Old != 0 {
+			c.Delta = (c.New - c.Old) / c.Old * 100
+		}
+		c.Significant = c.P < alpha
+		result = append(result, c)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch {
+		case a.Unit != b.Unit:
+			return unitLess(a.Unit, b.Unit)
+		case a.Package != b.Package:
+			return a.Package < b.Package
+		default:
+			return a.Benchmark < b.Benchmark
+		}
+	})
+	return result
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// standardUnits are the units reported by every benchmark, or by -benchmem.
+// They are sorted before any custom units.
+var standardUnits = map[string]int{"ns/op": 1, "B/op": 2, "allocs/op": 3}
+
+func unitLess(a, b string) bool {
+	orderA, orderB := standardUnits[a], standardUnits[b]
+	switch {
+	case orderA > 0 && orderB > 0:
+		return orderA < orderB
+	case orderA > 0 || orderB > 0:
+		return orderA > 0
+	default:
+		return a < b
+	}
+}
+
+var writers = map[string]func(io.Writer, []comparison) error{
+	"text": writeText,
+	"json": writeJSON,
+}
+
+func writeText(out io.Writer, comparisons []comparison) error {
+	if len(comparisons) == 0 {
+		_, err := fmt.Fprintln(out, "No benchmarks found in both files.")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	var unit string
+	for _, c := range comparisons {
+		if c.Unit != unit {
+			if unit != "" {
+				fmt.Fprintln(w)
+			}
+			unit = c.Unit
+			fmt.Fprintf(w, "=== %s\n", unit)
+			fmt.Fprintln(w, "OLD\tNEW\tDELTA\tP\tN\tNAME")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.3f\t%d+%d\t%s.%s\n",
+			formatValue(c.Old), formatValue(c.New), formatDelta(c),
+			c.P, c.OldCount, c.NewCount, c.Package, c.Benchmark)
+	}
+	return w.Flush()
+}
+
+func formatValue(value float64) string {
+	if math.Abs(value) >= 1000 {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.4g", value)
+}
+
+func formatDelta(c comparison) string {
+	if !c.Significant || c.Old == 0 {
+		return "~"
+	}
+	return fmt.Sprintf("%+.2f%%", c.Delta)
+}
+
+func writeJSON(out io.Writer, comparisons []comparison) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(comparisons)
+}
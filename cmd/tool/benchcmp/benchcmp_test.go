@@ -0,0 +1,97 @@
+package benchcmp
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/golden"
+)
+
+func TestUsage_WithFlagsFromSetupFlags(t *testing.T) {
+	defer env.PatchAll(t, nil)()
+
+	name := "gotestsum tool benchcmp"
+	flags, _ := setupFlags(name)
+	buf := new(bytes.Buffer)
+	usage(buf, name, flags)
+
+	golden.Assert(t, buf.String(), "cmd-flags-help-text")
+}
+
+func TestRun(t *testing.T) {
+	for _, format := range []string{"text", "json"} {
+		t.Run(format, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			opts := &options{
+				old:    "testdata/old.json",
+				new:    "testdata/new.json",
+				format: format,
+				alpha:  defaultAlpha,
+			}
+			err := run(opts, out)
+			assert.NilError(t, err)
+			golden.Assert(t, out.String(), "benchcmp-expected."+format)
+		})
+	}
+}
+
+func TestRun_InvalidOptions(t *testing.T) {
+	err := run(&options{format: "bogus", alpha: defaultAlpha}, new(bytes.Buffer))
+	assert.ErrorContains(t, err, "invalid --format value: bogus")
+
+	err = run(&options{format: "text", alpha: 1}, new(bytes.Buffer))
+	assert.ErrorContains(t, err, "--alpha must be between 0 and 1")
+}
+
+func TestMannWhitneyUTest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		x, y     []float64
+		expected float64
+	}{
+		{
+			name:     "no overlap, exact",
+			x:        []float64{1, 2, 3, 4, 5},
+			y:        []float64{6, 7, 8, 9, 10},
+			expected: 2.0 / 252,
+		},
+		{
+			name:     "one sample each",
+			x:        []float64{1},
+			y:        []float64{2},
+			expected: 1,
+		},
+		{
+			name:     "interleaved, exact",
+			x:        []float64{1, 4, 5},
+			y:        []float64{2, 3, 6},
+			expected: 1,
+		},
+		{
+			name:     "all equal",
+			x:        []float64{3, 3, 3},
+			y:        []float64{3, 3, 3},
+			expected: 1,
+		},
+		{
+			name:     "no overlap with ties, normal approximation",
+			x:        []float64{1, 1, 2, 2, 3},
+			y:        []float64{4, 4, 5, 5, 6},
+			expected: 0.01116,
+		},
+		{
+			name:     "empty",
+			y:        []float64{1},
+			expected: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := mannWhitneyUTest(tc.x, tc.y)
+			assert.Assert(t, actual > tc.expected-0.00001 && actual < tc.expected+0.00001,
+				"expected %v, got %v", tc.expected, actual)
+		})
+	}
+}
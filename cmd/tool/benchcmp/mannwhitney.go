@@ -0,0 +1,132 @@
+package benchcmp
+
+import (
+	"math"
+	"sort"
+)
+
+// maxExactSamples is the largest total number of samples for which the exact
+// distribution of U is used. Larger samples use the normal approximation.
+const maxExactSamples = 50
+
+// mannWhitneyUTest returns the two-sided p-value of the Mann-Whitney U test
+// for the null hypothesis that the samples x and y come from the same
+// distribution. Returns 1 if either sample is empty.
+//
+// When neither sample has ties, and the samples are small, the p-value is
+// calculated from the exact distribution of U. Otherwise the normal
+// approximation, with a correction for ties, is used.
+func mannWhitneyUTest(x, y []float64) float64 {
+	n1, n2 := len(x), len(y)
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	ranks, tieCorrection := rank(x, y)
+	var rankSumX float64
+	for i := range x {
+		rankSumX += ranks[i]
+	}
+	u := rankSumX - float64(n1*(n1+1))/2
+	if other := float64(n1*n2) - u; other < u {
+		u = other
+	}
+
+	var p float64
+	if tieCorrection == 0 && n1+n2 <= maxExactSamples {
+		p = 2 * exactUCDF(n1, n2, int(u))
+	} else {
+		p = normalUPValue(n1, n2, u, tieCorrection)
+	}
+	return math.Min(p, 1)
+}
+
+// rank returns the rank of every value in x followed by every value in y. Tied
+// values get the average of their ranks. The second return value is the sum
+// of t^3-t for every group of t tied values.
+func rank(x, y []float64) ([]float64, float64) {
+	type sample struct {
+		value float64
+		index int
+	}
+	samples := make([]sample, 0, len(x)+len(y))
+	for i, v := range append(append([]float64{}, x...), y...) {
+		samples = append(samples, sample{value: v, index: i})
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].value < samples[j].value
+	})
+
+	ranks := make([]float64, len(samples))
+	var tieCorrection float64
+	for start := 0; start < len(samples); {
+		end := start + 1
+		for end < len(samples) && samples[end].value == samples[start].value {
+			end++
+		}
+		// ranks start at 1, so the average rank of start..end-1 is:
+		avg := float64(start+end+1) / 2
+		for i := start; i < end; i++ {
+			ranks[samples[i].index] = avg
+		}
+		if t := float64(end - start); t > 1 {
+			tieCorrection += t*t*t - t
+		}
+		start = end
+	}
+	return ranks, tieCorrection
+}
+
+// exactUCDF returns the probability that U is less than or equal to u, for
+// samples of size n1 and n2 with no ties.
+func exactUCDF(n1, n2, u int) float64 {
+	// counts[i][j][k] is the number of orderings of i values from the first
+	// sample and j values from the second sample where U is k.
+	counts := make([][][]float64, n1+1)
+	for i := range counts {
+		counts[i] = make([][]float64, n2+1)
+		for j := range counts[i] {
+			counts[i][j] = make([]float64, i*j+1)
+			if i == 0 || j == 0 {
+				counts[i][j][0] = 1
+				continue
+			}
+			for k := range counts[i][j] {
+				// the largest value is from the second sample, and is greater
+				// than all i values from the first sample.
+				if k-i >= 0 {
+					counts[i][j][k] += counts[i][j-1][k-i]
+				}
+				// the largest value is from the first sample.
+				if k < len(counts[i-1][j]) {
+					counts[i][j][k] += counts[i-1][j][k]
+				}
+			}
+		}
+	}
+
+	var total, below float64
+	for k, count := range counts[n1][n2] {
+		total += count
+		if k <= u {
+			below += count
+		}
+	}
+	return below / total
+}
+
+// normalUPValue returns the two-sided p-value of u using the normal
+// approximation to the distribution of U, with a continuity correction.
+func normalUPValue(n1, n2 int, u, tieCorrection float64) float64 {
+	n := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * ((n + 1) - tieCorrection/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (mean - u - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		return 1
+	}
+	return math.Erfc(z / math.Sqrt2)
+}
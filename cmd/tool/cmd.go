@@ -5,6 +5,12 @@ import (
 	"os"
 
 	"gotest.tools/gotestsum/cmd"
+	"gotest.tools/gotestsum/cmd/tool/benchcmp"
+	"gotest.tools/gotestsum/cmd/tool/convert"
+	"gotest.tools/gotestsum/cmd/tool/diff"
+	"gotest.tools/gotestsum/cmd/tool/flaky"
+	"gotest.tools/gotestsum/cmd/tool/history"
+	"gotest.tools/gotestsum/cmd/tool/merge"
 	"gotest.tools/gotestsum/cmd/tool/slowest"
 )
 
@@ -17,6 +23,18 @@ func Run(name string, args []string) error {
 		return nil
 	case "slowest":
 		return slowest.Run(name+" "+next, rest)
+	case "history":
+		return history.Run(name+" "+next, rest)
+	case "flaky":
+		return flaky.Run(name+" "+next, rest)
+	case "diff":
+		return diff.Run(name+" "+next, rest)
+	case "benchcmp":
+		return benchcmp.Run(name+" "+next, rest)
+	case "convert":
+		return convert.Run(name+" "+next, rest)
+	case "merge":
+		return merge.Run(name+" "+next, rest)
 	default:
 		fmt.Fprintln(os.Stderr, usage(name))
 		return fmt.Errorf("invalid command: %v %v", name, next)
@@ -26,7 +44,7 @@ func Run(name string, args []string) error {
 func usage(name string) string {
 	return fmt.Sprintf(`Usage: %s COMMAND [flags]
 
-Commands: slowest
+Commands: slowest, history, flaky, diff, benchcmp, convert, merge
 
 Use '%s COMMAND --help' for command specific help.
 `, name, name)
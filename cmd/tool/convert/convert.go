@@ -0,0 +1,90 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/pflag"
+	"gotest.tools/gotestsum/cmd"
+	"gotest.tools/gotestsum/internal/jsonfile"
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Run reads test2json output from files, or stdin, and writes the reports
+// selected by flags, without running any tests.
+func Run(name string, args []string) error {
+	flags, opts := setupFlags(name)
+	switch err := flags.Parse(args); {
+	case err == pflag.ErrHelp:
+		return nil
+	case err != nil:
+		usage(os.Stderr, name, flags)
+		return err
+	}
+	opts.jsonfiles = flags.Args()
+	if opts.debug {
+		log.SetLevel(log.DebugLevel)
+	}
+	return run(opts)
+}
+
+type options struct {
+	jsonfiles []string
+	reports   *cmd.ReportFlags
+	debug     bool
+}
+
+func setupFlags(name string) (*pflag.FlagSet, *options) {
+	opts := &options{}
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	flags.Usage = func() {
+		usage(os.Stdout, name, flags)
+	}
+
+	opts.reports = cmd.SetupReportFlags(flags)
+	flags.BoolVar(&opts.debug, "debug", false, "enabled debug logging")
+	return flags, opts
+}
+
+func usage(out io.Writer, name string, flags *pflag.FlagSet) {
+	fmt.Fprintf(out, `Usage:
+    %[1]s [flags] [JSONFILE...]
+
+Read one or more json files and write reports, without running any tests.
+The json files may be created with 'gotestsum --jsonfile' or 'go test -json'.
+If no files are given, or a file is '-', the json is read from stdin.
+
+The files are read in order. Every file after the first is a re-run, and the
+tests in the file are assigned a RunID equal to the position of the file in the
+list, starting at 0. The output of a re-run should come after the output of the
+run which it re-ran. Use 'gotestsum tool merge' to combine files which are not
+re-runs.
+
+Flags:
+`, name)
+	flags.SetOutput(out)
+	flags.PrintDefaults()
+}
+
+func run(opts *options) error {
+	exec, err := opts.reports.NewExecution()
+	if err != nil {
+		return err
+	}
+
+	filenames := opts.jsonfiles
+	if len(filenames) == 0 {
+		filenames = []string{"-"}
+	}
+	for runID, filename := range filenames {
+		cfg := testjson.ScanConfig{RunID: runID, Execution: exec}
+		if err := jsonfile.Scan(cfg, filename); err != nil {
+			return err
+		}
+	}
+
+	return opts.reports.WriteReports(exec)
+}
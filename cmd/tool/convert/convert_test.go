@@ -0,0 +1,90 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"gotest.tools/gotestsum/summaryjson"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/golden"
+)
+
+func TestRun(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("run.json", `{"Package": "example.com/pkg", "Test": "TestFlaky", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestFlaky", "Action": "output", "Output": "flaky_test.go:10: failed\n"}
+{"Package": "example.com/pkg", "Test": "TestFlaky", "Action": "fail", "Elapsed": 0.1}
+{"Package": "example.com/pkg", "Test": "TestPassed", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestPassed", "Action": "pass", "Elapsed": 0.2}
+{"Package": "example.com/pkg", "Action": "fail", "Elapsed": 0.4}
+`),
+		fs.WithFile("rerun.json", `{"Package": "example.com/pkg", "Test": "TestFlaky", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestFlaky", "Action": "pass", "Elapsed": 0.3}
+{"Package": "example.com/pkg", "Action": "pass", "Elapsed": 0.5}
+`))
+	defer dir.Remove()
+
+	err := Run("convert", []string{
+		"--markdownfile", dir.Join("report.md"),
+		"--summary-json", dir.Join("summary.json"),
+		dir.Join("run.json"),
+		dir.Join("rerun.json"),
+	})
+	assert.NilError(t, err)
+
+	raw, err := ioutil.ReadFile(dir.Join("report.md"))
+	assert.NilError(t, err)
+	golden.Assert(t, string(raw), "convert-expected.markdown")
+
+	raw, err = ioutil.ReadFile(dir.Join("summary.json"))
+	assert.NilError(t, err)
+	var report summaryjson.Report
+	assert.NilError(t, json.Unmarshal(raw, &report))
+	assert.Equal(t, report.Total, 3)
+	assert.Equal(t, len(report.Failed), 1)
+	assert.Equal(t, report.Failed[0].RunID, 0)
+	expected := []summaryjson.Rerun{
+		{Package: "example.com/pkg", Test: "TestFlaky", Attempts: 2, Failures: 1, Result: "pass"},
+	}
+	assert.DeepEqual(t, report.Reruns, expected)
+}
+
+func TestRun_UsesTimeFromEvents(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("run.json", `{"Time": "2018-03-22T22:33:35.000Z", "Package": "example.com/pkg", "Test": "TestOne", "Action": "run"}
+{"Time": "2018-03-22T22:33:36.000Z", "Package": "example.com/pkg", "Test": "TestOne", "Action": "pass", "Elapsed": 1}
+{"Time": "2018-03-22T22:33:37.500Z", "Package": "example.com/pkg", "Action": "pass", "Elapsed": 2.5}
+`))
+	defer dir.Remove()
+
+	err := Run("convert", []string{
+		"--summary-json", dir.Join("summary.json"),
+		dir.Join("run.json"),
+	})
+	assert.NilError(t, err)
+
+	raw, err := ioutil.ReadFile(dir.Join("summary.json"))
+	assert.NilError(t, err)
+	var report summaryjson.Report
+	assert.NilError(t, json.Unmarshal(raw, &report))
+	assert.Equal(t, report.Started, time.Date(2018, 3, 22, 22, 33, 35, 0, time.UTC))
+	assert.Equal(t, report.Elapsed, 2.5)
+}
+
+func TestUsage_WithFlagsFromSetupFlags(t *testing.T) {
+	name := "gotestsum tool convert"
+	flags, _ := setupFlags(name)
+	buf := new(bytes.Buffer)
+	usage(buf, name, flags)
+
+	golden.Assert(t, buf.String(), "cmd-flags-help-text")
+}
+
+func TestRun_MissingFile(t *testing.T) {
+	err := Run("convert", []string{"./does-not-exist.json"})
+	assert.ErrorContains(t, err, "failed to read jsonfile")
+}
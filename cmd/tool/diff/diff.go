@@ -0,0 +1,320 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gotest.tools/gotestsum/internal/aggregate"
+	"gotest.tools/gotestsum/internal/jsonfile"
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Run the command
+func Run(name string, args []string) error {
+	flags, opts := setupFlags(name)
+	switch err := flags.Parse(args); {
+	case err == pflag.ErrHelp:
+		return nil
+	case err != nil:
+		usage(os.Stderr, name, flags)
+		return err
+	}
+	if flags.NArg() != 2 {
+		usage(os.Stderr, name, flags)
+		return fmt.Errorf("expected 2 arguments, BASE and HEAD, got %d", flags.NArg())
+	}
+	opts.base, opts.head = flags.Arg(0), flags.Arg(1)
+	if opts.base == "-" && opts.head == "-" {
+		return fmt.Errorf("only one of BASE and HEAD can be read from stdin")
+	}
+	return run(opts, os.Stdout)
+}
+
+const defaultThreshold = 100 * time.Millisecond
+
+func setupFlags(name string) (*pflag.FlagSet, *options) {
+	opts := &options{}
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	flags.Usage = func() {
+		usage(os.Stdout, name, flags)
+	}
+	flags.StringVar(&opts.format, "format", "text",
+		"output format, one of: text, json")
+	flags.DurationVar(&opts.threshold, "threshold", defaultThreshold,
+		"tests with an elapsed time which increased by more than threshold are slower")
+	flags.BoolVar(&opts.debug, "debug", false,
+		"enable debug logging.")
+	return flags, opts
+}
+
+func usage(out io.Writer, name string, flags *pflag.FlagSet) {
+	fmt.Fprintf(out, `Usage:
+    %[1]s [flags] BASE HEAD
+
+Read two json files, BASE and HEAD, and print the tests which changed between
+the two runs. The json files may be created with 'gotestsum --jsonfile' or
+'go test -json', and may be compressed with gzip. If BASE or HEAD is '-', the
+json is read from stdin.
+
+The tests are grouped into:
+  new failures   - tests which failed in HEAD, but not in BASE
+  fixed          - tests which failed in BASE, and passed in HEAD
+  new skips      - tests which were skipped in HEAD, but not in BASE
+  added          - tests which are only in HEAD
+  removed        - tests which are only in BASE
+  slower         - tests which passed in both, and the elapsed time in HEAD
+                   is more than threshold greater than the elapsed time in BASE
+
+A test which was run more than once, for example by 'gotestsum --rerun-fails',
+passed if any run passed. The elapsed time is the median of all the runs.
+
+Flags:
+`, name)
+	flags.SetOutput(out)
+	flags.PrintDefaults()
+}
+
+type options struct {
+	base      string
+	head      string
+	format    string
+	threshold time.Duration
+	debug     bool
+}
+
+func run(opts *options, out io.Writer) error {
+	if opts.debug {
+		log.SetLevel(log.DebugLevel)
+	}
+	write, ok := writers[opts.format]
+	if !ok {
+		return fmt.Errorf("invalid --format value: %v", opts.format)
+	}
+
+	base, err := resultsFromJSONFile(opts.base)
+	if err != nil {
+		return err
+	}
+	head, err := resultsFromJSONFile(opts.head)
+	if err != nil {
+		return err
+	}
+	return write(out, diffResults(base, head, opts.threshold))
+}
+
+type testKey struct {
+	pkg  string
+	test string
+}
+
+type result struct {
+	action  testjson.Action
+	elapsed time.Duration
+}
+
+// results is the result of every test from a single json file.
+type results map[testKey]result
+
+func resultsFromJSONFile(filename string) (results, error) {
+	in, err := jsonfile.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jsonfile: %v", err)
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			log.Errorf("Failed to close file %v: %v", filename, err)
+		}
+	}()
+
+	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{Stdout: in})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan testjson: %v", err)
+	}
+	return newResults(exec), nil
+}
+
+func newResults(exec *testjson.Execution) results {
+	r := make(results)
+	for _, name := range exec.Packages() {
+		pkg := exec.Package(name)
+		for _, tc := range pkg.Skipped {
+			r.add(tc, testjson.ActionSkip)
+		}
+		for _, tc := range pkg.Failed {
+			r.add(tc, testjson.ActionFail)
+		}
+		for _, tc := range pkg.Passed {
+			r.add(tc, testjson.ActionPass)
+		}
+		for _, tc := range aggregate.ByElapsed(pkg.TestCases()) {
+			key := testKey{pkg: tc.Package, test: tc.Test.Name()}
+			res := r[key]
+			res.elapsed = tc.Elapsed
+			r[key] = res
+		}
+	}
+	return r
+}
+
+// add the result of a test case. Results are added in order of precedence, so
+// a later result replaces an earlier result.
+func (r results) add(tc testjson.TestCase, action testjson.Action) {
+	key := testKey{pkg: tc.Package, test: tc.Test.Name()}
+	r[key] = result{action: action}
+}
+
+// changes is the list of tests which changed between the two runs.
+type changes struct {
+	NewFailures []changedTest `json:"newFailures"`
+	Fixed       []changedTest `json:"fixed"`
+	NewSkips    []changedTest `json:"newSkips"`
+	Added       []changedTest `json:"added"`
+	Removed     []changedTest `json:"removed"`
+	Slower      []changedTest `json:"slower"`
+}
+
+// changedTest is a test which changed between the two runs.
+type changedTest struct {
+	Package string `json:"package"`
+	Test    string `json:"test"`
+	// Base is the result in the BASE run. Empty if the test was added.
+	Base testjson.Action `json:"base,omitempty"`
+	// Head is the result in the HEAD run. Empty if the test was removed.
+	Head testjson.Action `json:"head,omitempty"`
+	// BaseElapsed is the elapsed time in seconds in the BASE run.
+	BaseElapsed float64 `json:"baseElapsed"`
+	// HeadElapsed is the elapsed time in seconds in the HEAD run.
+	HeadElapsed float64 `json:"headElapsed"`
+}
+
+func (t changedTest) String() string {
+	return t.Package + "." + t.Test
+}
+
+func diffResults(base, head results, threshold time.Duration) changes {
+	var d changes
+	for key, b := range base {
+		h, ok := head[key]
+		if !ok {
+			d.Removed = append(d.Removed, newTest(key, b, result{}))
+			continue
+		}
+		tc := newTest(key, b, h)
+		switch {
+		case h.action == testjson.ActionFail && b.action != testjson.ActionFail:
+			d.NewFailures = append(d.NewFailures, tc)
+		case b.action == testjson.ActionFail && h.action == testjson.ActionPass:
+			d.Fixed = append(d.Fixed, tc)
+		case h.action == testjson.ActionSkip && b.action != testjson.ActionSkip:
+			d.NewSkips = append(d.NewSkips, tc)
+		case b.action == testjson.ActionPass && h.action == testjson.ActionPass &&
+			h.elapsed-b.elapsed > threshold:
+			d.Slower = append(d.Slower, tc)
+		}
+	}
+	for key, h := range head {
+		if _, ok := base[key]; !ok {
+			d.Added = append(d.Added, newTest(key, result{}, h))
+		}
+	}
+
+	for _, tests := range [][]changedTest{d.NewFailures, d.Fixed, d.NewSkips, d.Added, d.Removed} {
+		sortByName(tests)
+	}
+	sort.Slice(d.Slower, func(i, j int) bool {
+		a, b := d.Slower[i], d.Slower[j]
+		if deltaA, deltaB := a.HeadElapsed-a.BaseElapsed, b.HeadElapsed-b.BaseElapsed; deltaA != deltaB {
+			return deltaA > deltaB
+		}
+		return a.String() < b.String()
+	})
+	return d
+}
+
+func newTest(key testKey, base, head result) changedTest {
+	return changedTest{
+		Package:     key.pkg,
+		Test:        key.test,
+		Base:        base.action,
+		Head:        head.action,
+		BaseElapsed: base.elapsed.Seconds(),
+		HeadElapsed: head.elapsed.Seconds(),
+	}
+}
+
+func sortByName(tests []changedTest) {
+	sort.Slice(tests, func(i, j int) bool {
+		return tests[i].String() < tests[j].String()
+	})
+}
+
+var writers = map[string]func(io.Writer, changes) error{
+	"text": writeText,
+	"json": writeJSON,
+}
+
+func writeText(out io.Writer, d changes) error {
+	sections := []struct {
+		header string
+		tests  []changedTest
+		format func(changedTest) string
+	}{
+		{header: "New failures", tests: d.NewFailures, format: formatResults},
+		{header: "Fixed", tests: d.Fixed, format: formatResults},
+		{header: "New skips", tests: d.NewSkips, format: formatResults},
+		{header: "Added", tests: d.Added, format: formatResults},
+		{header: "Removed", tests: d.Removed, format: formatResults},
+		{header: "Slower", tests: d.Slower, format: formatElapsed},
+	}
+
+	var count int
+	for _, section := range sections {
+		if len(section.tests) == 0 {
+			continue
+		}
+		if count > 0 {
+			fmt.Fprintln(out)
+		}
+		count++
+		fmt.Fprintf(out, "=== %s (%d)\n", section.header, len(section.tests))
+		for _, tc := range section.tests {
+			if _, err := fmt.Fprintf(out, "%s %s\n", tc, section.format(tc)); err != nil {
+				return err
+			}
+		}
+	}
+	if count == 0 {
+		_, err := fmt.Fprintln(out, "No changes found.")
+		return err
+	}
+	return nil
+}
+
+func formatResults(tc changedTest) string {
+	return fmt.Sprintf("(%s -> %s)", formatAction(tc.Base), formatAction(tc.Head))
+}
+
+func formatAction(action testjson.Action) string {
+	if action == "" {
+		return "none"
+	}
+	return string(action)
+}
+
+func formatElapsed(tc changedTest) string {
+	return fmt.Sprintf("(%.2fs -> %.2fs, +%.2fs)",
+		tc.BaseElapsed, tc.HeadElapsed, tc.HeadElapsed-tc.BaseElapsed)
+}
+
+func writeJSON(out io.Writer, d changes) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
@@ -0,0 +1,95 @@
+package diff
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/golden"
+)
+
+func TestUsage_WithFlagsFromSetupFlags(t *testing.T) {
+	defer env.PatchAll(t, nil)()
+
+	name := "gotestsum tool diff"
+	flags, _ := setupFlags(name)
+	buf := new(bytes.Buffer)
+	usage(buf, name, flags)
+
+	golden.Assert(t, buf.String(), "cmd-flags-help-text")
+}
+
+func TestRun(t *testing.T) {
+	for _, format := range []string{"text", "json"} {
+		t.Run(format, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			opts := &options{
+				base:      "testdata/base.json",
+				head:      "testdata/head.json",
+				format:    format,
+				threshold: defaultThreshold,
+			}
+			err := run(opts, out)
+			assert.NilError(t, err)
+			golden.Assert(t, out.String(), "diff-expected."+format)
+		})
+	}
+}
+
+func TestRun_FromStdinAndGzipFile(t *testing.T) {
+	raw, err := ioutil.ReadFile("testdata/head.json")
+	assert.NilError(t, err)
+	compressed := new(bytes.Buffer)
+	gz := gzip.NewWriter(compressed)
+	_, err = gz.Write(raw)
+	assert.NilError(t, err)
+	assert.NilError(t, gz.Close())
+
+	dir := fs.NewDir(t, t.Name(), fs.WithFile("head.json.gz", compressed.String()))
+	defer dir.Remove()
+
+	stdin, err := os.Open("testdata/base.json")
+	assert.NilError(t, err)
+	defer stdin.Close() // nolint: errcheck
+	orig := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = orig }()
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		base:      "-",
+		head:      dir.Join("head.json.gz"),
+		format:    "text",
+		threshold: defaultThreshold,
+	}
+	assert.NilError(t, run(opts, out))
+	golden.Assert(t, out.String(), "diff-expected.text")
+}
+
+func TestRun_BothFromStdin(t *testing.T) {
+	err := Run("diff", []string{"-", "-"})
+	assert.Error(t, err, "only one of BASE and HEAD can be read from stdin")
+}
+
+func TestRun_NoChanges(t *testing.T) {
+	out := new(bytes.Buffer)
+	opts := &options{
+		base:      "testdata/base.json",
+		head:      "testdata/base.json",
+		format:    "text",
+		threshold: defaultThreshold,
+	}
+	err := run(opts, out)
+	assert.NilError(t, err)
+	assert.Equal(t, out.String(), "No changes found.\n")
+}
+
+func TestRun_InvalidFormat(t *testing.T) {
+	err := run(&options{format: "bogus"}, new(bytes.Buffer))
+	assert.ErrorContains(t, err, "invalid --format value: bogus")
+}
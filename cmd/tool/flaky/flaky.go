@@ -0,0 +1,304 @@
+package flaky
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	"gotest.tools/gotestsum/internal/history"
+	"gotest.tools/gotestsum/internal/jsonfile"
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Run the command
+func Run(name string, args []string) error {
+	flags, opts := setupFlags(name)
+	switch err := flags.Parse(args); {
+	case err == pflag.ErrHelp:
+		return nil
+	case err != nil:
+		usage(os.Stderr, name, flags)
+		return err
+	}
+	opts.jsonfiles = flags.Args()
+	return run(opts, os.Stdout)
+}
+
+func setupFlags(name string) (*pflag.FlagSet, *options) {
+	opts := &options{}
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	flags.Usage = func() {
+		usage(os.Stdout, name, flags)
+	}
+	flags.StringVar(&opts.historyFile, "historyfile", "",
+		"path to a history file written by 'gotestsum --historyfile'")
+	flags.StringVar(&opts.format, "format", "text",
+		"output format, one of: text, json, markdown")
+	flags.BoolVar(&opts.debug, "debug", false,
+		"enable debug logging.")
+	return flags, opts
+}
+
+func usage(out io.Writer, name string, flags *pflag.FlagSet) {
+	fmt.Fprintf(out, `Usage:
+    %[1]s [flags] [JSONFILE...]
+
+Read one or more json files, and print the tests which are flaky. A test is
+flaky when it both passed and failed in the same json file, for example when it
+passed after being re-run by 'gotestsum --rerun-fails', or when it both passed
+and failed for the same git commit in the history file.
+
+The json files may be created with 'gotestsum --jsonfile' or 'go test -json'.
+Each json file is considered a separate run of the tests. If no json files or
+history file are given, the json is read from stdin.
+
+Flaky tests are sorted by flake rate, the number of failures divided by the
+number of runs, from highest to lowest.
+
+Flags:
+`, name)
+	flags.SetOutput(out)
+	flags.PrintDefaults()
+}
+
+type options struct {
+	jsonfiles   []string
+	historyFile string
+	format      string
+	debug       bool
+}
+
+func run(opts *options, out io.Writer) error {
+	if opts.debug {
+		log.SetLevel(log.DebugLevel)
+	}
+	write, ok := writers[opts.format]
+	if !ok {
+		return fmt.Errorf("invalid --format value: %v", opts.format)
+	}
+
+	var groups []group
+	jsonfiles := opts.jsonfiles
+	if len(jsonfiles) == 0 && opts.historyFile == "" {
+		jsonfiles = []string{"-"}
+	}
+	for _, filename := range jsonfiles {
+		g, err := groupFromJSONFile(filename)
+		if err != nil {
+			return err
+		}
+		groups = append(groups, g)
+	}
+	if opts.historyFile != "" {
+		g, err := groupsFromHistoryFile(opts.historyFile)
+		if err != nil {
+			return err
+		}
+		groups = append(groups, g...)
+	}
+	return write(out, findFlakyTests(groups))
+}
+
+type testKey struct {
+	pkg  string
+	test string
+}
+
+type counts struct {
+	passed int
+	failed int
+}
+
+// group is the results of every test from a single execution or commit.
+type group map[testKey]counts
+
+func (g group) add(key testKey, result testjson.Action) {
+	c := g[key]
+	switch result {
+	case testjson.ActionPass:
+		c.passed++
+	case testjson.ActionFail:
+		c.failed++
+	default:
+		return
+	}
+	g[key] = c
+}
+
+func groupFromJSONFile(filename string) (group, error) {
+	in, err := jsonfile.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jsonfile: %v", err)
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			log.Errorf("Failed to close file %v: %v", filename, err)
+		}
+	}()
+
+	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{Stdout: in})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan testjson: %v", err)
+	}
+
+	g := make(group)
+	for _, name := range exec.Packages() {
+		pkg := exec.Package(name)
+		for _, tc := range pkg.Passed {
+			g.add(testKey{pkg: tc.Package, test: tc.Test.Name()}, testjson.ActionPass)
+		}
+		for _, tc := range pkg.Failed {
+			g.add(testKey{pkg: tc.Package, test: tc.Test.Name()}, testjson.ActionFail)
+		}
+	}
+	return g, nil
+}
+
+// groupsFromHistoryFile returns a group for each commit in the history file.
+// Records without a commit are grouped by the time of the run.
+func groupsFromHistoryFile(filename string) ([]group, error) {
+	fh, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %v", err)
+	}
+	defer fh.Close() // nolint: errcheck
+
+	records, err := history.Read(fh)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []group // nolint: prealloc
+	index := make(map[string]group)
+	for _, record := range records {
+		id := record.Commit
+		if id == "" {
+			id = record.Time.String()
+		}
+		g, ok := index[id]
+		if !ok {
+			g = make(group)
+			index[id] = g
+			groups = append(groups, g)
+		}
+		g.add(testKey{pkg: record.Package, test: record.Test}, record.Result)
+	}
+	return groups, nil
+}
+
+// flakyTest is a test which both passed and failed in at least one group.
+type flakyTest struct {
+	Package string `json:"package"`
+	Test    string `json:"test"`
+	// Runs is the number of times the test passed or failed in all groups.
+	Runs int `json:"runs"`
+	// Failures is the number of times the test failed in all groups.
+	Failures int `json:"failures"`
+	// FlakyRuns is the number of groups where the test both passed and failed.
+	FlakyRuns int `json:"flakyRuns"`
+}
+
+// FlakeRate is the number of failures divided by the number of runs.
+func (f flakyTest) FlakeRate() float64 {
+	if f.Runs == 0 {
+		return 0
+	}
+	return float64(f.Failures) / float64(f.Runs)
+}
+
+func findFlakyTests(groups []group) []flakyTest {
+	totals := make(map[testKey]*flakyTest)
+	for _, g := range groups {
+		for key, c := range g {
+			total, ok := totals[key]
+			if !ok {
+				total = &flakyTest{Package: key.pkg, Test: key.test}
+				totals[key] = total
+			}
+			total.Runs += c.passed + c.failed
+			total.Failures += c.failed
+			if c.passed > 0 && c.failed > 0 {
+				total.FlakyRuns++
+			}
+		}
+	}
+
+	var result []flakyTest // nolint: prealloc
+	for _, total := range totals {
+		if total.FlakyRuns > 0 {
+			result = append(result, *total)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		switch {
+		case a.FlakeRate() != b.FlakeRate():
+			return a.FlakeRate() > b.FlakeRate()
+		case a.Runs != b.Runs:
+			return a.Runs > b.Runs
+		case a.Package != b.Package:
+			return a.Package < b.Package
+		}
+		return a.Test < b.Test
+	})
+	return result
+}
+
+var writers = map[string]func(io.Writer, []flakyTest) error{
+	"text":     writeText,
+	"json":     writeJSON,
+	"markdown": writeMarkdown,
+}
+
+func writeText(out io.Writer, tests []flakyTest) error {
+	buf := new(strings.Builder)
+	w := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FLAKE RATE\tFAILURES\tRUNS\tFLAKY RUNS\tTEST")
+	for _, tc := range tests {
+		fmt.Fprintf(w, "%.1f%%\t%d\t%d\t%d\t%s\n",
+			tc.FlakeRate()*100, tc.Failures, tc.Runs, tc.FlakyRuns, tc.Package+"."+tc.Test)
+	}
+	_ = w.Flush()
+
+	_, err := io.WriteString(out, buf.String())
+	return err
+}
+
+func writeJSON(out io.Writer, tests []flakyTest) error {
+	type flakyTestJSON struct {
+		flakyTest
+		FlakeRate float64 `json:"flakeRate"`
+	}
+	result := make([]flakyTestJSON, 0, len(tests))
+	for _, tc := range tests {
+		result = append(result, flakyTestJSON{flakyTest: tc, FlakeRate: tc.FlakeRate()})
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+func writeMarkdown(out io.Writer, tests []flakyTest) error {
+	if len(tests) == 0 {
+		_, err := fmt.Fprintln(out, "No flaky tests found.")
+		return err
+	}
+	buf := new(strings.Builder)
+	buf.WriteString("| Test | Flake rate | Failures | Runs | Flaky runs |\n")
+	buf.WriteString("| --- | ---: | ---: | ---: | ---: |\n")
+	for _, tc := range tests {
+		fmt.Fprintf(buf, "| `%s` | %.1f%% | %d | %d | %d |\n",
+			strings.Replace(tc.Package+"."+tc.Test, "|", `\|`, -1),
+			tc.FlakeRate()*100, tc.Failures, tc.Runs, tc.FlakyRuns)
+	}
+
+	_, err := io.WriteString(out, buf.String())
+	return err
+}
@@ -0,0 +1,63 @@
+package flaky
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/golden"
+)
+
+func TestUsage_WithFlagsFromSetupFlags(t *testing.T) {
+	defer env.PatchAll(t, nil)()
+
+	name := "gotestsum tool flaky"
+	flags, _ := setupFlags(name)
+	buf := new(bytes.Buffer)
+	usage(buf, name, flags)
+
+	golden.Assert(t, buf.String(), "cmd-flags-help-text")
+}
+
+func TestRun(t *testing.T) {
+	for _, format := range []string{"text", "json", "markdown"} {
+		t.Run(format, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			opts := &options{
+				jsonfiles:   []string{"testdata/run-1.json", "testdata/run-2.json"},
+				historyFile: "testdata/history.json",
+				format:      format,
+			}
+			err := run(opts, out)
+			assert.NilError(t, err)
+			golden.Assert(t, out.String(), "flaky-expected."+format)
+		})
+	}
+}
+
+func TestRun_InvalidFormat(t *testing.T) {
+	err := run(&options{format: "bogus"}, new(bytes.Buffer))
+	assert.ErrorContains(t, err, "invalid --format value: bogus")
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestRun_WriteError(t *testing.T) {
+	for _, format := range []string{"text", "json", "markdown"} {
+		t.Run(format, func(t *testing.T) {
+			opts := &options{
+				jsonfiles:   []string{"testdata/run-1.json", "testdata/run-2.json"},
+				historyFile: "testdata/history.json",
+				format:      format,
+			}
+			err := run(opts, errWriter{})
+			assert.Error(t, err, "write failed")
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gotest.tools/gotestsum/internal/aggregate"
+	"gotest.tools/gotestsum/internal/history"
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Run the command
+func Run(name string, args []string) error {
+	flags, opts := setupFlags(name)
+	switch err := flags.Parse(args); {
+	case err == pflag.ErrHelp:
+		return nil
+	case err != nil:
+		usage(os.Stderr, name, flags)
+		return err
+	}
+	return run(opts, os.Stdout)
+}
+
+func setupFlags(name string) (*pflag.FlagSet, *options) {
+	opts := &options{}
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	flags.Usage = func() {
+		usage(os.Stdout, name, flags)
+	}
+	flags.StringVar(&opts.historyFile, "historyfile", os.Getenv("GOTESTSUM_HISTORYFILE"),
+		"path to the history file written by 'gotestsum --historyfile'")
+	flags.StringVar(&opts.pkg, "package", "",
+		"only show tests from this package")
+	flags.StringVar(&opts.run, "run", "",
+		"only show tests with a name that matches this regular expression")
+	flags.BoolVar(&opts.debug, "debug", false,
+		"enable debug logging.")
+	return flags, opts
+}
+
+func usage(out io.Writer, name string, flags *pflag.FlagSet) {
+	fmt.Fprintf(out, `Usage:
+    %[1]s [flags]
+
+Read the test history file written by 'gotestsum --historyfile' and print a
+summary of every test case in the history.
+
+The summary includes the pass rate of the test (passed runs divided by passed
+and failed runs), the number of runs, the median elapsed time, the trend of the
+elapsed time, the time of the last failure, and the time the test was first seen.
+
+The trend compares the median elapsed time of the most recent half of the runs
+to the median elapsed time of the oldest half of the runs. A trend is only shown
+for tests with at least 4 runs.
+
+Tests are sorted by pass rate, so that tests which fail most often are printed
+first. All times are printed in UTC.
+
+Flags:
+`, name)
+	flags.SetOutput(out)
+	flags.PrintDefaults()
+}
+
+type options struct {
+	historyFile string
+	pkg         string
+	run         string
+	debug       bool
+}
+
+func run(opts *options, out io.Writer) error {
+	if opts.debug {
+		log.SetLevel(log.DebugLevel)
+	}
+	if opts.historyFile == "" {
+		return fmt.Errorf("a history file is required, use --historyfile")
+	}
+	match, err := regexp.Compile(opts.run)
+	if err != nil {
+		return fmt.Errorf("invalid --run value: %v", err)
+	}
+
+	fh, err := os.Open(opts.historyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read history file: %v", err)
+	}
+	defer fh.Close() // nolint: errcheck
+
+	records, err := history.Read(fh)
+	if err != nil {
+		return err
+	}
+
+	var filtered []history.Record
+	for _, record := range records {
+		if opts.pkg != "" && record.Package != opts.pkg {
+			continue
+		}
+		if !match.MatchString(record.Test) {
+			continue
+		}
+		filtered = append(filtered, record)
+	}
+	return writeSummaries(out, summarize(filtered))
+}
+
+type testSummary struct {
+	pkg         string
+	test        string
+	passed      int
+	failed      int
+	skipped     int
+	elapsed     []time.Duration
+	firstSeen   time.Time
+	lastFailure time.Time
+}
+
+func (s testSummary) passRate() float64 {
+	if s.passed+s.failed == 0 {
+		return 1
+	}
+	return float64(s.passed) / float64(s.passed+s.failed)
+}
+
+// trend returns the change in the median elapsed time, as a ratio, between
+// the oldest and the most recent half of the runs. Returns false if there are
+// not enough runs to show a trend.
+func (s testSummary) trend() (float64, bool) {
+	if len(s.elapsed) < 4 {
+		return 0, false
+	}
+	half := len(s.elapsed) / 2
+	before := aggregate.Median(append([]time.Duration{}, s.elapsed[:half]...))
+	after := aggregate.Median(append([]time.Duration{}, s.elapsed[len(s.elapsed)-half:]...))
+	if before == 0 {
+		return 0, false
+	}
+	return float64(after-before) / float64(before), true
+}
+
+// summarize the records by package and test name. Records must be in the
+// order they were run.
+func summarize(records []history.Record) []testSummary {
+	type key struct {
+		pkg  string
+		test string
+	}
+	index := make(map[key]int)
+	var result []testSummary
+	for _, record := range records {
+		k := key{pkg: record.Package, test: record.Test}
+		i, ok := index[k]
+		if !ok {
+			i = len(result)
+			index[k] = i
+			result = append(result, testSummary{
+				pkg:       record.Package,
+				test:      record.Test,
+				firstSeen: record.Time,
+			})
+		}
+		s := &result[i]
+		if record.Time.Before(s.firstSeen) {
+			s.firstSeen = record.Time
+		}
+
+		switch record.Result {
+		case testjson.ActionSkip:
+			s.skipped++
+			continue
+		case testjson.ActionFail:
+			s.failed++
+			if record.Time.After(s.lastFailure) {
+				s.lastFailure = record.Time
+			}
+		default:
+			s.passed++
+		}
+		s.elapsed = append(s.elapsed, record.ElapsedDuration())
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.passRate() != b.passRate() {
+			return a.passRate() < b.passRate()
+		}
+		if a.pkg != b.pkg {
+			return a.pkg < b.pkg
+		}
+		return a.test < b.test
+	})
+	return result
+}
+
+const timeFormat = "2006-01-02 15:04"
+
+func writeSummaries(out io.Writer, summaries []testSummary) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PASS RATE\tRUNS\tMEDIAN\tTREND\tLAST FAILURE\tFIRST SEEN\tTEST")
+	for _, s := range summaries {
+		trend := "-"
+		if v, ok := s.trend(); ok {
+			trend = fmt.Sprintf("%+.0f%%", v*100)
+		}
+		lastFailure := "-"
+		if !s.lastFailure.IsZero() {
+			lastFailure = s.lastFailure.UTC().Format(timeFormat)
+		}
+		fmt.Fprintf(w, "%.1f%%\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			s.passRate()*100,
+			s.passed+s.failed,
+			aggregate.Median(append([]time.Duration{}, s.elapsed...)),
+			trend,
+			lastFailure,
+			s.firstSeen.UTC().Format(timeFormat),
+			s.pkg+"."+s.test)
+	}
+	return w.Flush()
+}
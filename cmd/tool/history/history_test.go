@@ -0,0 +1,58 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/golden"
+)
+
+func TestUsage_WithFlagsFromSetupFlags(t *testing.T) {
+	defer env.PatchAll(t, nil)()
+
+	name := "gotestsum tool history"
+	flags, _ := setupFlags(name)
+	buf := new(bytes.Buffer)
+	usage(buf, name, flags)
+
+	golden.Assert(t, buf.String(), "cmd-flags-help-text")
+}
+
+func TestRun(t *testing.T) {
+	historyFile := fs.NewFile(t, t.Name(), fs.WithContent(historyRecords))
+	defer historyFile.Remove()
+
+	out := new(bytes.Buffer)
+	err := run(&options{historyFile: historyFile.Path()}, out)
+	assert.NilError(t, err)
+	golden.Assert(t, out.String(), "history-summary-expected")
+}
+
+func TestRun_WithFilters(t *testing.T) {
+	historyFile := fs.NewFile(t, t.Name(), fs.WithContent(historyRecords))
+	defer historyFile.Remove()
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		historyFile: historyFile.Path(),
+		pkg:         "example.com/pkg/a",
+		run:         "^TestFlaky$",
+	}
+	err := run(opts, out)
+	assert.NilError(t, err)
+	golden.Assert(t, out.String(), "history-summary-filtered-expected")
+}
+
+const historyRecords = `{"time":"2020-10-01T10:00:00Z","commit":"aaa","pkg":"example.com/pkg/a","test":"TestFlaky","result":"pass","elapsed":1}
+{"time":"2020-10-01T10:00:00Z","commit":"aaa","pkg":"example.com/pkg/a","test":"TestStable","result":"pass","elapsed":0.1}
+{"time":"2020-10-01T10:00:00Z","commit":"aaa","pkg":"example.com/pkg/b","test":"TestSkipped","result":"skip","elapsed":0}
+{"time":"2020-10-02T10:00:00Z","commit":"bbb","pkg":"example.com/pkg/a","test":"TestFlaky","result":"fail","elapsed":1.2}
+{"time":"2020-10-02T10:00:00Z","commit":"bbb","pkg":"example.com/pkg/a","test":"TestFlaky","result":"pass","elapsed":1.4,"run":1}
+{"time":"2020-10-02T10:00:00Z","commit":"bbb","pkg":"example.com/pkg/a","test":"TestStable","result":"pass","elapsed":0.1}
+{"time":"2020-10-03T10:00:00Z","commit":"ccc","pkg":"example.com/pkg/a","test":"TestFlaky","result":"pass","elapsed":2}
+{"time":"2020-10-03T10:00:00Z","commit":"ccc","pkg":"example.com/pkg/a","test":"TestStable","result":"pass","elapsed":0.1}
+{"time":"2020-10-03T10:00:00Z","commit":"ccc","pkg":"example.com/pkg/b","test":"TestNew","result":"fail","elapsed":0.5}
+`
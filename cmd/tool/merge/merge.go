@@ -0,0 +1,285 @@
+package merge
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gotest.tools/gotestsum/cmd"
+	"gotest.tools/gotestsum/internal/jsonfile"
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Run reads test2json output from many files, and combines them into a single
+// Execution. The combined Execution is used to print a summary, and to write
+// reports.
+func Run(name string, args []string) error {
+	flags, opts := setupFlags(name)
+	switch err := flags.Parse(args); {
+	case err == pflag.ErrHelp:
+		return nil
+	case err != nil:
+		usage(os.Stderr, name, flags)
+		return err
+	}
+	opts.jsonfiles = flags.Args()
+	if opts.debug {
+		log.SetLevel(log.DebugLevel)
+	}
+	return run(opts)
+}
+
+type options struct {
+	jsonfiles   []string
+	jsonFile    string
+	hideSummary []string
+	reports     *cmd.ReportFlags
+	debug       bool
+
+	// shims for testing
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func setupFlags(name string) (*pflag.FlagSet, *options) {
+	opts := &options{stdout: os.Stdout, stderr: os.Stderr}
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	flags.SetInterspersed(false)
+	flags.Usage = func() {
+		usage(os.Stdout, name, flags)
+	}
+
+	flags.StringVar(&opts.jsonFile, "jsonfile", "",
+		"write all the TestEvents from every file to a single file")
+	flags.StringSliceVar(&opts.hideSummary, "hide-summary", nil,
+		"hide sections of the summary: "+testjson.SummarizeAll.String())
+	opts.reports = cmd.SetupReportFlags(flags)
+	flags.BoolVar(&opts.debug, "debug", false, "enabled debug logging")
+	return flags, opts
+}
+
+func usage(out io.Writer, name string, flags *pflag.FlagSet) {
+	fmt.Fprintf(out, `Usage:
+    %[1]s [flags] JSONFILE...
+
+Read many json files, from 'gotestsum --jsonfile' or 'go test -json', and
+combine them into a single test run. A summary of the combined run is printed
+to stdout, and any reports selected by flags are written.
+
+The RunID of every event is kept as it is in the file, and is stored in every
+event written to --jsonfile, so the merged file can be read by 'gotestsum tool
+convert'.
+
+Tests which appear in more than one file are printed to stderr. The exit code
+is 1 when any test failed, unless the same file has a later pass for the test,
+for example because it was re-run by 'gotestsum --rerun-fails'.
+
+Flags:
+`, name)
+	flags.SetOutput(out)
+	flags.PrintDefaults()
+}
+
+func run(opts *options) error {
+	if len(opts.jsonfiles) == 0 {
+		return fmt.Errorf("at least one jsonfile is required")
+	}
+	summary, err := summaryFromFlag(opts.hideSummary)
+	if err != nil {
+		return err
+	}
+
+	exec, err := opts.reports.NewExecution()
+	if err != nil {
+		return err
+	}
+
+	handler, err := newMergeHandler(opts.jsonFile)
+	if err != nil {
+		return err
+	}
+	defer handler.Close() // nolint: errcheck
+
+	for index, filename := range opts.jsonfiles {
+		handler.file = index
+		cfg := testjson.ScanConfig{Execution: exec, Handler: handler}
+		if err := jsonfile.Scan(cfg, filename); err != nil {
+			return err
+		}
+	}
+	if err := handler.Close(); err != nil {
+		return err
+	}
+
+	writeDuplicateTests(opts.stderr, duplicateTests(handler.files, opts.jsonfiles))
+	testjson.PrintSummary(opts.stdout, exec, summary)
+	if err := opts.reports.WriteReports(exec); err != nil {
+		return err
+	}
+	return cmd.FailedExitErr(exec, handler.sameFile)
+}
+
+// summaryFromFlag returns the sections of the summary to print, from the
+// sections hidden by --hide-summary.
+func summaryFromFlag(hide []string) (testjson.Summary, error) {
+	result := testjson.SummarizeAll
+	for _, item := range hide {
+		summary, ok := testjson.NewSummary(item)
+		if !ok {
+			return result, fmt.Errorf("invalid --hide-summary value %q, must be one or more of: %s",
+				item, testjson.SummarizeAll.String())
+		}
+		result &^= summary
+	}
+	return result, nil
+}
+
+// mergeHandler records the file which contains each test, and writes every
+// TestEvent, including the RunID, to a file.
+type mergeHandler struct {
+	out     io.WriteCloser
+	encoder *json.Encoder
+	// file is the index of the file being scanned.
+	file int
+	// files is the index of every file which contains a top-level test.
+	files map[mergeTestKey]map[int]struct{}
+	// caseFiles is the index of the file which contains the pass or fail
+	// event of each TestCase.
+	caseFiles map[mergeCaseKey]int
+}
+
+type mergeTestKey struct {
+	pkg  string
+	test string
+}
+
+type mergeCaseKey struct {
+	pkg string
+	id  int
+}
+
+func newMergeHandler(filename string) (*mergeHandler, error) {
+	handler := &mergeHandler{
+		files:     make(map[mergeTestKey]map[int]struct{}),
+		caseFiles: make(map[mergeCaseKey]int),
+	}
+	if filename == "" {
+		return handler, nil
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JSON file: %v", err)
+	}
+	handler.out, handler.encoder = f, json.NewEncoder(f)
+	return handler, nil
+}
+
+func (h *mergeHandler) Event(event testjson.TestEvent, exec *testjson.Execution) error {
+	if event.Test != "" {
+		h.recordFile(event, exec)
+	}
+	if h.encoder == nil {
+		return nil
+	}
+	if err := h.encoder.Encode(event); err != nil {
+		return fmt.Errorf("failed to write JSON file: %v", err)
+	}
+	return nil
+}
+
+// recordFile records the index of the file being scanned for the test in event.
+func (h *mergeHandler) recordFile(event testjson.TestEvent, exec *testjson.Execution) {
+	switch event.Action {
+	case testjson.ActionRun:
+		if testjson.TestName(event.Test).IsSubTest() {
+			return
+		}
+		key := mergeTestKey{pkg: event.Package, test: event.Test}
+		if h.files[key] == nil {
+			h.files[key] = make(map[int]struct{})
+		}
+		h.files[key][h.file] = struct{}{}
+	case testjson.ActionPass:
+		if tc, ok := lastByName(exec.Package(event.Package).Passed, event.Test); ok {
+			h.caseFiles[mergeCaseKey{pkg: tc.Package, id: tc.ID}] = h.file
+		}
+	case testjson.ActionFail:
+		if tc, ok := lastByName(exec.Package(event.Package).Failed, event.Test); ok {
+			h.caseFiles[mergeCaseKey{pkg: tc.Package, id: tc.ID}] = h.file
+		}
+	}
+}
+
+// sameFile returns true if the pass or fail events of both test cases were
+// read from the same file.
+func (h *mergeHandler) sameFile(a, b testjson.TestCase) bool {
+	fileA, okA := h.caseFiles[mergeCaseKey{pkg: a.Package, id: a.ID}]
+	fileB, okB := h.caseFiles[mergeCaseKey{pkg: b.Package, id: b.ID}]
+	return okA && okB && fileA == fileB
+}
+
+func lastByName(cases []testjson.TestCase, name string) (testjson.TestCase, bool) {
+	for i := len(cases) - 1; i >= 0; i-- {
+		if cases[i].Test.Name() == name {
+			return cases[i], true
+		}
+	}
+	return testjson.TestCase{}, false
+}
+
+func (h *mergeHandler) Err(text string) error {
+	return nil
+}
+
+func (h *mergeHandler) Close() error {
+	if h.out == nil {
+		return nil
+	}
+	out := h.out
+	h.out, h.encoder = nil, nil
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close JSON file: %v", err)
+	}
+	return nil
+}
+
+type duplicateTest struct {
+	name      string
+	filenames []string
+}
+
+// duplicateTests returns the top-level tests which appear in more than one of
+// the files. files is the index of every file which contains each test.
+func duplicateTests(files map[mergeTestKey]map[int]struct{}, filenames []string) []duplicateTest {
+	var result []duplicateTest
+	for key, indexes := range files {
+		if len(indexes) < 2 {
+			continue
+		}
+		dup := duplicateTest{name: key.pkg + "." + key.test}
+		for index := range indexes {
+			dup.filenames = append(dup.filenames, filenames[index])
+		}
+		sort.Strings(dup.filenames)
+		result = append(result, dup)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+func writeDuplicateTests(out io.Writer, dups []duplicateTest) {
+	if len(dups) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "Found %d tests in more than one file:\n", len(dups))
+	for _, dup := range dups {
+		fmt.Fprintf(out, "  %s: %s\n", dup.name, strings.Join(dup.filenames, ", "))
+	}
+}
@@ -0,0 +1,195 @@
+package merge
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+
+	"gotest.tools/gotestsum/cmd"
+	"gotest.tools/gotestsum/internal/jsonfile"
+	"gotest.tools/gotestsum/summaryjson"
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/golden"
+)
+
+func TestUsage_WithFlagsFromSetupFlags(t *testing.T) {
+	name := "gotestsum tool merge"
+	flags, _ := setupFlags(name)
+	buf := new(bytes.Buffer)
+	usage(buf, name, flags)
+
+	golden.Assert(t, buf.String(), "cmd-flags-help-text")
+}
+
+func TestMerge(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("a.json", `{"Package": "example.com/a", "Test": "TestOne", "Action": "run"}
+{"Package": "example.com/a", "Test": "TestOne", "Action": "pass", "Elapsed": 0.1}
+{"Package": "example.com/a", "Test": "TestTwo", "Action": "run"}
+{"Package": "example.com/a", "Test": "TestTwo", "Action": "fail", "Elapsed": 0.1}
+{"Package": "example.com/a", "Action": "fail", "Elapsed": 0.2}
+`),
+		fs.WithFile("b.json", `{"Package": "example.com/a", "Test": "TestTwo", "Action": "run"}
+{"Package": "example.com/a", "Test": "TestTwo", "Action": "pass", "Elapsed": 0.1}
+{"Package": "example.com/a", "Action": "pass", "Elapsed": 0.2}
+{"Package": "example.com/b", "Test": "TestThree", "Action": "run"}
+{"Package": "example.com/b", "Test": "TestThree", "Action": "pass", "Elapsed": 0.1}
+{"Package": "example.com/b", "Action": "pass", "Elapsed": 0.1}
+`))
+	defer dir.Remove()
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	flags, opts := setupFlags("merge")
+	err := flags.Parse([]string{
+		"--jsonfile", dir.Join("merged.json"),
+		dir.Join("a.json"),
+		dir.Join("b.json"),
+	})
+	assert.NilError(t, err)
+	opts.jsonfiles = flags.Args()
+	opts.stdout, opts.stderr = stdout, stderr
+
+	err = run(opts)
+	assert.Error(t, err, "1 tests failed")
+	assert.Equal(t, cmd.ExitCodeWithDefault(err), 1)
+
+	expected := "Found 1 tests in more than one file:\n" +
+		"  example.com/a.TestTwo: " + dir.Join("a.json") + ", " + dir.Join("b.json") + "\n"
+	assert.Equal(t, stderr.String(), expected)
+	assert.Assert(t, strings.Contains(stdout.String(), "DONE 4 tests, 1 failure"),
+		stdout.String())
+}
+
+func TestMerge_DisjointShards(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("a.json", `{"Package": "example.com/a", "Test": "TestA", "Action": "run"}
+{"Package": "example.com/a", "Test": "TestA", "Action": "pass", "Elapsed": 0.1}
+{"Package": "example.com/a", "Action": "pass", "Elapsed": 0.2}
+`),
+		fs.WithFile("b.json", `{"Package": "example.com/b", "Test": "TestB", "Action": "run"}
+{"Package": "example.com/b", "Test": "TestB", "Action": "fail", "Elapsed": 0.1}
+{"Package": "example.com/b", "Action": "fail", "Elapsed": 0.2}
+`))
+	defer dir.Remove()
+
+	stdout := new(bytes.Buffer)
+	flags, opts := setupFlags("merge")
+	err := flags.Parse([]string{
+		"--summary-json", dir.Join("summary.json"),
+		dir.Join("a.json"),
+		dir.Join("b.json"),
+	})
+	assert.NilError(t, err)
+	opts.jsonfiles = flags.Args()
+	opts.stdout, opts.stderr = stdout, new(bytes.Buffer)
+
+	err = run(opts)
+	assert.Error(t, err, "1 tests failed")
+
+	out := stdout.String()
+	assert.Assert(t, strings.Contains(out, "=== FAIL: example.com/b TestB (0.10s)"), out)
+	assert.Assert(t, !strings.Contains(out, "re-run"), out)
+	assert.Assert(t, strings.Contains(out, "DONE 2 tests, 1 failure"), out)
+
+	raw, err := ioutil.ReadFile(dir.Join("summary.json"))
+	assert.NilError(t, err)
+	var report summaryjson.Report
+	assert.NilError(t, json.Unmarshal(raw, &report))
+	assert.Equal(t, len(report.Reruns), 0)
+}
+
+func TestMerge_KeepsRunIDFromEvents(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("a.json", `{"Package": "example.com/a", "Test": "TestFlaky", "Action": "run"}
+{"Package": "example.com/a", "Test": "TestFlaky", "Action": "fail", "Elapsed": 0.1}
+{"Package": "example.com/a", "Action": "fail", "Elapsed": 0.2}
+{"Package": "example.com/a", "Test": "TestFlaky", "Action": "run", "RunID": 1}
+{"Package": "example.com/a", "Test": "TestFlaky", "Action": "pass", "Elapsed": 0.1, "RunID": 1}
+{"Package": "example.com/a", "Action": "pass", "Elapsed": 0.2, "RunID": 1}
+`),
+		fs.WithFile("b.json", `{"Package": "example.com/b", "Test": "TestB", "Action": "run"}
+{"Package": "example.com/b", "Test": "TestB", "Action": "pass", "Elapsed": 0.1}
+{"Package": "example.com/b", "Action": "pass", "Elapsed": 0.2}
+`))
+	defer dir.Remove()
+
+	stdout := new(bytes.Buffer)
+	flags, opts := setupFlags("merge")
+	err := flags.Parse([]string{
+		"--jsonfile", dir.Join("merged.json"),
+		dir.Join("a.json"),
+		dir.Join("b.json"),
+	})
+	assert.NilError(t, err)
+	opts.jsonfiles = flags.Args()
+	opts.stdout, opts.stderr = stdout, new(bytes.Buffer)
+
+	assert.NilError(t, run(opts))
+	assert.Assert(t, strings.Contains(stdout.String(), "DONE 2 runs, 3 tests, 1 failure"),
+		stdout.String())
+
+	exec := testjson.NewExecution()
+	err = jsonfile.Scan(testjson.ScanConfig{Execution: exec}, dir.Join("merged.json"))
+	assert.NilError(t, err)
+
+	var runIDs []int
+	for _, name := range exec.Packages() {
+		for _, tc := range exec.Package(name).TestCases() {
+			runIDs = append(runIDs, tc.RunID)
+		}
+	}
+	sort.Ints(runIDs)
+	assert.DeepEqual(t, runIDs, []int{0, 0, 1})
+}
+
+// rerun-fails.json is the --jsonfile from 'gotestsum --rerun-fails', where
+// TestFlaky failed and then passed when it was re-run.
+func TestMerge_JSONFileFromRerunFails(t *testing.T) {
+	stdout := new(bytes.Buffer)
+	flags, opts := setupFlags("merge")
+	assert.NilError(t, flags.Parse([]string{"testdata/rerun-fails.json"}))
+	opts.jsonfiles = flags.Args()
+	opts.stdout, opts.stderr = stdout, new(bytes.Buffer)
+
+	assert.NilError(t, run(opts))
+	out := stdout.String()
+	assert.Assert(t, strings.Contains(out, "DONE 3 tests, 1 failure in 0.134s"), out)
+}
+
+// A pass from a later file is not a re-run of a test which failed in an earlier
+// file.
+func TestMerge_JSONFileFromRerunFails_FailedInAnotherFile(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("b.json", `{"Package": "example.com/rr", "Test": "TestPass", "Action": "run"}
+{"Package": "example.com/rr", "Test": "TestPass", "Action": "fail", "Elapsed": 0.1}
+{"Package": "example.com/rr", "Action": "fail", "Elapsed": 0.2}
+`))
+	defer dir.Remove()
+
+	flags, opts := setupFlags("merge")
+	err := flags.Parse([]string{dir.Join("b.json"), "testdata/rerun-fails.json"})
+	assert.NilError(t, err)
+	opts.jsonfiles = flags.Args()
+	opts.stdout, opts.stderr = new(bytes.Buffer), new(bytes.Buffer)
+
+	err = run(opts)
+	assert.Error(t, err, "1 tests failed")
+	assert.Equal(t, cmd.ExitCodeWithDefault(err), 1)
+}
+
+func TestMerge_InvalidHideSummary(t *testing.T) {
+	err := Run("merge", []string{"--hide-summary", "bogus", "testdata/rerun-fails.json"})
+	assert.Error(t, err, `invalid --hide-summary value "bogus", must be one or more of: `+
+		testjson.SummarizeAll.String())
+}
+
+func TestMerge_NoFiles(t *testing.T) {
+	err := Run("merge", nil)
+	assert.Error(t, err, "at least one jsonfile is required")
+}
@@ -0,0 +1,111 @@
+package slowest
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"gotest.tools/gotestsum/internal/aggregate"
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+func runBaseline(opts *options, exec *testjson.Execution, out io.Writer) error {
+	f, err := os.Open(opts.baseline)
+	if err != nil {
+		return fmt.Errorf("failed to read baseline: %v", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			log.Errorf("Failed to close file %v: %v", opts.baseline, err)
+		}
+	}()
+
+	baseline, err := testjson.ScanTestOutput(testjson.ScanConfig{Stdout: f})
+	if err != nil {
+		return fmt.Errorf("failed to scan baseline testjson: %v", err)
+	}
+
+	regressions := findRegressions(timings(baseline), timings(exec), opts)
+	if len(regressions) == 0 {
+		return nil
+	}
+	if err := writeRegressions(out, regressions); err != nil {
+		return err
+	}
+	return fmt.Errorf("%d tests or packages are slower than the baseline", len(regressions))
+}
+
+// timings returns the elapsed time of every test, and every package, in exec.
+// Tests are keyed by package and test name, and packages by package name. If
+// a test ran more than once the median elapsed time is used. The elapsed time
+// of a package is the time reported by 'go test' for the package.
+func timings(exec *testjson.Execution) map[string]time.Duration {
+	result := make(map[string]time.Duration)
+	for _, name := range exec.Packages() {
+		pkg := exec.Package(name)
+		for _, tc := range aggregate.ByElapsed(pkg.TestCases()) {
+			result[name+"."+tc.Test.Name()] = tc.Elapsed
+		}
+		if elapsed := pkg.ElapsedFromEvent(); elapsed > 0 {
+			result[name] = elapsed
+		}
+	}
+	return result
+}
+
+// regression is a test or package which is slower than the baseline.
+type regression struct {
+	name     string
+	baseline time.Duration
+	current  time.Duration
+}
+
+func (r regression) increase() time.Duration {
+	return r.current - r.baseline
+}
+
+func (r regression) ratio() float64 {
+	return float64(r.current) / float64(r.baseline)
+}
+
+// findRegressions returns the tests and packages which are slower than the
+// baseline, sorted by the increase in elapsed time, largest first. Tests which
+// are not in the baseline are ignored.
+func findRegressions(baseline, current map[string]time.Duration, opts *options) []regression {
+	var result []regression
+	for name, elapsed := range current {
+		base, ok := baseline[name]
+		if !ok || base <= 0 || elapsed <= opts.threshold {
+			continue
+		}
+		r := regression{name: name, baseline: base, current: elapsed}
+		if opts.maxRatio > 0 && r.ratio() <= opts.maxRatio {
+			continue
+		}
+		if opts.maxIncrease > 0 && r.increase() <= opts.maxIncrease {
+			continue
+		}
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].increase() != result[j].increase() {
+			return result[i].increase() > result[j].increase()
+		}
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+func writeRegressions(out io.Writer, regressions []regression) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INCREASE\tRATIO\tBASELINE\tCURRENT\tNAME")
+	for _, r := range regressions {
+		fmt.Fprintf(w, "+%v\t%.2fx\t%v\t%v\t%s\n",
+			r.increase(), r.ratio(), r.baseline, r.current, r.name)
+	}
+	return w.Flush()
+}
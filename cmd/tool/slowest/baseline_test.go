@@ -0,0 +1,69 @@
+package slowest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestRunBaseline(t *testing.T) {
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("baseline.json", `{"Package": "example.com/pkg", "Test": "TestSame", "Action": "pass", "Elapsed": 1.0}
+{"Package": "example.com/pkg", "Test": "TestSlower", "Action": "pass", "Elapsed": 1.0}
+{"Package": "example.com/pkg", "Test": "TestSlower/sub", "Action": "pass", "Elapsed": 0.5}
+{"Package": "example.com/pkg", "Test": "TestFast", "Action": "pass", "Elapsed": 0.01}
+{"Package": "example.com/pkg", "Action": "pass", "Elapsed": 2.0}
+`))
+	defer dir.Remove()
+
+	current := `{"Package": "example.com/pkg", "Test": "TestSame", "Action": "pass", "Elapsed": 1.1}
+{"Package": "example.com/pkg", "Test": "TestSlower", "Action": "pass", "Elapsed": 3.0}
+{"Package": "example.com/pkg", "Test": "TestSlower/sub", "Action": "pass", "Elapsed": 2.5}
+{"Package": "example.com/pkg", "Test": "TestFast", "Action": "pass", "Elapsed": 0.05}
+{"Package": "example.com/pkg", "Test": "TestNew", "Action": "pass", "Elapsed": 5.0}
+{"Package": "example.com/pkg", "Action": "pass", "Elapsed": 9.0}
+`
+	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{Stdout: strings.NewReader(current)})
+	assert.NilError(t, err)
+
+	out := new(bytes.Buffer)
+	opts := &options{
+		baseline:    dir.Join("baseline.json"),
+		threshold:   100 * time.Millisecond,
+		maxRatio:    1.5,
+		maxIncrease: time.Second,
+	}
+	err = runBaseline(opts, exec, out)
+	assert.Error(t, err, "3 tests or packages are slower than the baseline")
+
+	expected := `INCREASE  RATIO  BASELINE  CURRENT  NAME
++7s       4.50x  2s        9s       example.com/pkg
++2s       3.00x  1s        3s       example.com/pkg.TestSlower
++2s       5.00x  500ms     2.5s     example.com/pkg.TestSlower/sub
+`
+	assert.Equal(t, out.String(), expected)
+
+	t.Run("no regressions", func(t *testing.T) {
+		out := new(bytes.Buffer)
+		opts := *opts
+		opts.maxIncrease = time.Hour
+		assert.NilError(t, runBaseline(&opts, exec, out))
+		assert.Equal(t, out.String(), "")
+	})
+}
+
+func TestOptions_Validate(t *testing.T) {
+	opts := options{baseline: "base.json"}
+	assert.Error(t, opts.validate(), "--baseline requires --max-ratio or --max-increase")
+
+	opts = options{baseline: "base.json", maxRatio: 2, skipStatement: "testing.Short"}
+	assert.Error(t, opts.validate(), "--baseline can not be used with --skip-stmt")
+
+	opts = options{baseline: "base.json", maxRatio: 2}
+	assert.NilError(t, opts.validate())
+}
@@ -3,12 +3,13 @@ package slowest
 import (
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"sort"
 	"time"
 
 	"github.com/spf13/pflag"
+	"gotest.tools/gotestsum/internal/aggregate"
+	"gotest.tools/gotestsum/internal/jsonfile"
 	"gotest.tools/gotestsum/log"
 	"gotest.tools/gotestsum/testjson"
 )
@@ -39,6 +40,12 @@ func setupFlags(name string) (*pflag.FlagSet, *options) {
 		"test cases with elapsed time greater than threshold are slow tests")
 	flags.StringVar(&opts.skipStatement, "skip-stmt", "",
 		"add this go statement to slow tests, instead of printing the list of slow tests")
+	flags.StringVar(&opts.baseline, "baseline", "",
+		"path to test2json output from a previous run, print tests and packages which are slower than the baseline")
+	flags.Float64Var(&opts.maxRatio, "max-ratio", 0,
+		"with --baseline, a test is slower when the elapsed time divided by the baseline is greater than max-ratio")
+	flags.DurationVar(&opts.maxIncrease, "max-increase", 0,
+		"with --baseline, a test is slower when the elapsed time minus the baseline is greater than max-increase")
 	flags.BoolVar(&opts.debug, "debug", false,
 		"enable debug logging.")
 	return flags, opts
@@ -82,6 +89,17 @@ Alternatively, a custom --skip-stmt may be provided as a string:
 Note that this tool does not add imports, so using a custom statement may require
 you to add imports to the file.
 
+If --baseline is set, the elapsed time of every test and package is compared to
+the elapsed time in the baseline json file, and a table of the tests and packages
+which are slower than the baseline is printed, sorted by the increase in elapsed
+time. The command exits with an error if any test or package is slower. The
+elapsed time of a package is the elapsed time reported by 'go test' for the package.
+Only tests with an elapsed time greater than threshold are compared. At least one
+of --max-ratio or --max-increase must be set. When both are set, a test is only
+slower when it exceeds both limits.
+
+    %[1]s --jsonfile current.json --baseline main.json --max-ratio 1.5
+
 Go build flags, such as build tags, may be set using the GOFLAGS environment
 variable, following the same rules as the go toolchain. See
 https://golang.org/cmd/go/#hdr-Environment_variables.
@@ -96,14 +114,33 @@ type options struct {
 	threshold     time.Duration
 	jsonfile      string
 	skipStatement string
+	baseline      string
+	maxRatio      float64
+	maxIncrease   time.Duration
 	debug         bool
 }
 
+func (o options) validate() error {
+	if o.baseline == "" {
+		return nil
+	}
+	switch {
+	case o.skipStatement != "":
+		return fmt.Errorf("--baseline can not be used with --skip-stmt")
+	case o.maxRatio <= 0 && o.maxIncrease <= 0:
+		return fmt.Errorf("--baseline requires --max-ratio or --max-increase")
+	}
+	return nil
+}
+
 func run(opts *options) error {
 	if opts.debug {
 		log.SetLevel(log.DebugLevel)
 	}
-	in, err := jsonfileReader(opts.jsonfile)
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	in, err := jsonfile.Open(opts.jsonfile)
 	if err != nil {
 		return fmt.Errorf("failed to read jsonfile: %v", err)
 	}
@@ -118,6 +155,10 @@ func run(opts *options) error {
 		return fmt.Errorf("failed to scan testjson: %v", err)
 	}
 
+	if opts.baseline != "" {
+		return runBaseline(opts, exec, os.Stdout)
+	}
+
 	tcs := slowTestCases(exec, opts.threshold)
 	if opts.skipStatement != "" {
 		skipStmt, err := parseSkipStatement(opts.skipStatement)
@@ -146,7 +187,7 @@ func slowTestCases(exec *testjson.Execution, threshold time.Duration) []testjson
 	pkgs := exec.Packages()
 	tests := make([]testjson.TestCase, 0, len(pkgs))
 	for _, pkg := range pkgs {
-		pkgTests := aggregateTestCases(exec.Package(pkg).TestCases())
+		pkgTests := aggregate.ByElapsed(exec.Package(pkg).TestCases())
 		tests = append(tests, pkgTests...)
 	}
 	sort.Slice(tests, func(i, j int) bool {
@@ -157,50 +198,3 @@ func slowTestCases(exec *testjson.Execution, threshold time.Duration) []testjson
 	})
 	return tests[:end]
 }
-
-// collectTestCases maps all test cases by name, and if there is more than one
-// instance of a TestCase, finds the median elapsed time for all the runs.
-//
-// All cases are assumed to be part of the same package.
-func aggregateTestCases(cases []testjson.TestCase) []testjson.TestCase {
-	if len(cases) < 2 {
-		return cases
-	}
-	pkg := cases[0].Package
-	// nolint: prealloc // size is not predictable
-	m := make(map[testjson.TestName][]time.Duration)
-	for _, tc := range cases {
-		m[tc.Test] = append(m[tc.Test], tc.Elapsed)
-	}
-	result := make([]testjson.TestCase, 0, len(m))
-	for name, timing := range m {
-		result = append(result, testjson.TestCase{
-			Package: pkg,
-			Test:    name,
-			Elapsed: median(timing),
-		})
-	}
-	return result
-}
-
-func median(times []time.Duration) time.Duration {
-	switch len(times) {
-	case 0:
-		return 0
-	case 1:
-		return times[0]
-	}
-	sort.Slice(times, func(i, j int) bool {
-		return times[i] < times[j]
-	})
-	return times[len(times)/2]
-}
-
-func jsonfileReader(v string) (io.ReadCloser, error) {
-	switch v {
-	case "", "-":
-		return ioutil.NopCloser(os.Stdin), nil
-	default:
-		return os.Open(v)
-	}
-}
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"strconv"
+	"strings"
+
+	"gotest.tools/gotestsum/summaryjson"
 )
 
 func main() {
@@ -43,6 +47,9 @@ func main() {
 		"-group", "gotestsum",
 		"-subtitle", subtitle,
 	}
+	if message := failedTestsMessage(); message != "" {
+		args = append(args, "-message", message)
+	}
 	log.Printf("terminal-notifier %#v", args)
 	err := exec.Command("terminal-notifier", args...).Run()
 	if err != nil {
@@ -58,3 +65,31 @@ func envInt(name string) int {
 	}
 	return n
 }
+
+// maxFailedTests is the maximum number of failed tests to include in the
+// notification message.
+const maxFailedTests = 3
+
+// failedTestsMessage reads the summary report from stdin, and returns a
+// message with the names of the failed tests.
+func failedTestsMessage() string {
+	var report summaryjson.Report
+	if err := json.NewDecoder(os.Stdin).Decode(&report); err != nil {
+		log.Printf("Failed to read summary from stdin: %v", err)
+		return ""
+	}
+
+	var names []string
+	for _, tc := range report.Failed {
+		if len(names) == maxFailedTests {
+			names = append(names, fmt.Sprintf("and %d more", len(report.Failed)-maxFailedTests))
+			break
+		}
+		name := tc.Test
+		if name == "" {
+			name = tc.Package
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
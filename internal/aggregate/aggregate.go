@@ -0,0 +1,50 @@
+/*Package aggregate summarizes the test cases from one or more test runs.
+ */
+package aggregate
+
+import (
+	"sort"
+	"time"
+
+	"gotest.tools/gotestsum/testjson"
+)
+
+// ByElapsed maps all test cases by name, and if there is more than one
+// instance of a TestCase, finds the median elapsed time for all the runs.
+//
+// All cases are assumed to be part of the same package.
+func ByElapsed(cases []testjson.TestCase) []testjson.TestCase {
+	if len(cases) < 2 {
+		return cases
+	}
+	pkg := cases[0].Package
+	// nolint: prealloc // size is not predictable
+	m := make(map[testjson.TestName][]time.Duration)
+	for _, tc := range cases {
+		m[tc.Test] = append(m[tc.Test], tc.Elapsed)
+	}
+	result := make([]testjson.TestCase, 0, len(m))
+	for name, timing := range m {
+		result = append(result, testjson.TestCase{
+			Package: pkg,
+			Test:    name,
+			Elapsed: Median(timing),
+		})
+	}
+	return result
+}
+
+// Median returns the median value of times. If there are an even number of
+// values the larger of the two middle values is returned.
+func Median(times []time.Duration) time.Duration {
+	switch len(times) {
+	case 0:
+		return 0
+	case 1:
+		return times[0]
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i] < times[j]
+	})
+	return times[len(times)/2]
+}
@@ -0,0 +1,86 @@
+/*Package benchjson reads and writes a JSON file of the benchmark results from
+a testjson.Execution.
+*/
+package benchjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Version of the Report document. The version is incremented when a field is
+// removed or changed in a way that is not backwards compatible.
+const Version = 1
+
+// Report is the result of every benchmark in a test execution.
+type Report struct {
+	Version int `json:"version"`
+	// Benchmarks is the result of every run of every benchmark, sorted by
+	// package. A benchmark run with -count has one Benchmark for each run.
+	Benchmarks []Benchmark `json:"benchmarks"`
+}
+
+// Benchmark is the result of a single run of a benchmark.
+type Benchmark struct {
+	Package string `json:"package"`
+	// Name of the benchmark, without the GOMAXPROCS suffix.
+	Name string `json:"name"`
+	// Procs is the value of GOMAXPROCS used to run the benchmark.
+	Procs int `json:"procs,omitempty"`
+	// Iterations is the number of times the benchmark loop ran (b.N).
+	Iterations int `json:"iterations"`
+	// Metrics are the values reported by the benchmark indexed by unit,
+	// ex: ns/op, B/op, allocs/op, and any custom metrics.
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// FullName returns the name of the benchmark with the GOMAXPROCS suffix, as it
+// is printed by 'go test'.
+func (b Benchmark) FullName() string {
+	return testjson.Benchmark{Name: b.Name, Procs: b.Procs}.FullName()
+}
+
+// New returns a Report of the benchmarks in the execution.
+func New(exec *testjson.Execution) Report {
+	report := Report{Version: Version, Benchmarks: []Benchmark{}}
+	for _, b := range exec.Benchmarks() {
+		metrics := make(map[string]float64, len(b.Metrics))
+		for _, m := range b.Metrics {
+			metrics[m.Unit] = m.Value
+		}
+		report.Benchmarks = append(report.Benchmarks, Benchmark{
+			Package:    b.Package,
+			Name:       b.Name,
+			Procs:      b.Procs,
+			Iterations: b.Iterations,
+			Metrics:    metrics,
+		})
+	}
+	return report
+}
+
+// Write creates a JSON Report and writes it to out.
+func Write(out io.Writer, exec *testjson.Execution) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(New(exec)); err != nil {
+		return fmt.Errorf("failed to write benchmark JSON: %v", err)
+	}
+	return nil
+}
+
+// Read a JSON Report from r.
+func Read(r io.Reader) (Report, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return report, fmt.Errorf("failed to read benchmark JSON: %v", err)
+	}
+	if report.Version > Version {
+		return report, fmt.Errorf("unsupported benchmark JSON version %d, expected %d or lower",
+			report.Version, Version)
+	}
+	return report, nil
+}
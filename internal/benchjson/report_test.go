@@ -0,0 +1,40 @@
+package benchjson
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+)
+
+func TestWrite(t *testing.T) {
+	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{
+		Stdout: strings.NewReader(`{"Package": "example.com/one", "Test": "BenchmarkAlloc", "Action": "run"}
+{"Package": "example.com/one", "Test": "BenchmarkAlloc", "Action": "output", "Output": "BenchmarkAlloc-8 \t 3145728\t 381.2 ns/op\t 128 B/op\t 2 allocs/op\n"}
+{"Package": "example.com/one", "Action": "output", "Output": "BenchmarkAlloc-8 \t 3000000\t 392.5 ns/op\t 128 B/op\t 2 allocs/op\n"}
+{"Package": "example.com/one", "Action": "pass"}
+{"Package": "example.com/two", "Test": "BenchmarkParse", "Action": "run"}
+{"Package": "example.com/two", "Test": "BenchmarkParse", "Action": "output", "Output": "BenchmarkParse \t 812345\t 1460 ns/op\t 3.000 tokens/op\n"}
+{"Package": "example.com/two", "Action": "pass"}
+`),
+	})
+	assert.NilError(t, err)
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, Write(out, exec))
+	golden.Assert(t, out.String(), "bench-report.golden")
+
+	report, err := Read(out)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, report, New(exec))
+	assert.Equal(t, report.Benchmarks[0].FullName(), "BenchmarkAlloc-8")
+	assert.Equal(t, report.Benchmarks[2].FullName(), "BenchmarkParse")
+}
+
+func TestRead_UnsupportedVersion(t *testing.T) {
+	_, err := Read(strings.NewReader(`{"version": 2, "benchmarks": []}`))
+	assert.Error(t, err, "unsupported benchmark JSON version 2, expected 1 or lower")
+}
@@ -0,0 +1,153 @@
+package coverprofile
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"time"
+
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Cobertura is the root element of a Cobertura XML report.
+type Cobertura struct {
+	XMLName         xml.Name           `xml:"coverage"`
+	LineRate        string             `xml:"line-rate,attr"`
+	BranchRate      string             `xml:"branch-rate,attr"`
+	LinesCovered    int                `xml:"lines-covered,attr"`
+	LinesValid      int                `xml:"lines-valid,attr"`
+	BranchesCovered int                `xml:"branches-covered,attr"`
+	BranchesValid   int                `xml:"branches-valid,attr"`
+	Complexity      string             `xml:"complexity,attr"`
+	Version         string             `xml:"version,attr"`
+	Timestamp       int64              `xml:"timestamp,attr"`
+	Sources         []string           `xml:"sources>source"`
+	Packages        []CoberturaPackage `xml:"packages>package"`
+}
+
+// CoberturaPackage is the coverage of a single Go package.
+type CoberturaPackage struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   string           `xml:"line-rate,attr"`
+	BranchRate string           `xml:"branch-rate,attr"`
+	Complexity string           `xml:"complexity,attr"`
+	Classes    []CoberturaClass `xml:"classes>class"`
+}
+
+// CoberturaClass is the coverage of a single source file.
+type CoberturaClass struct {
+	Name       string          `xml:"name,attr"`
+	Filename   string          `xml:"filename,attr"`
+	LineRate   string          `xml:"line-rate,attr"`
+	BranchRate string          `xml:"branch-rate,attr"`
+	Complexity string          `xml:"complexity,attr"`
+	Methods    struct{}        `xml:"methods"`
+	Lines      []CoberturaLine `xml:"lines>line"`
+}
+
+// CoberturaLine is the coverage of a single line in a source file.
+type CoberturaLine struct {
+	Number int `xml:"number,attr"`
+	Hits   int `xml:"hits,attr"`
+}
+
+// WriteCobertura writes a Cobertura XML report of the profile to out. The
+// filename of every class is relative to the root of the module, which is
+// also the only source.
+func WriteCobertura(out io.Writer, profile *Profile, timestamp time.Time) error {
+	report, err := newCobertura(profile, timestamp)
+	if err != nil {
+		return err
+	}
+	if err := writeCobertura(out, report); err != nil {
+		return fmt.Errorf("failed to write Cobertura XML: %v", err)
+	}
+	return nil
+}
+
+func newCobertura(profile *Profile, timestamp time.Time) (Cobertura, error) {
+	files, err := profile.Files()
+	if err != nil {
+		return Cobertura{}, err
+	}
+
+	report := Cobertura{
+		BranchRate: "0",
+		Complexity: "0",
+		Timestamp:  timestamp.UnixNano() / int64(time.Millisecond),
+		Sources:    []string{"."},
+	}
+	pkgIndex := make(map[string]int)
+	pkgLines := make(map[string][2]int)
+	for _, f := range files {
+		pkgName := path.Dir(f.Name)
+		i, ok := pkgIndex[pkgName]
+		if !ok {
+			i = len(report.Packages)
+			pkgIndex[pkgName] = i
+			report.Packages = append(report.Packages, CoberturaPackage{
+				Name:       pkgName,
+				BranchRate: "0",
+				Complexity: "0",
+			})
+		}
+
+		class := CoberturaClass{
+			Name:       path.Base(f.Name),
+			Filename:   relativeFilename(f.Name),
+			LineRate:   lineRate(f.Covered(), len(f.Lines)),
+			BranchRate: "0",
+			Complexity: "0",
+		}
+		for _, line := range f.Lines {
+			class.Lines = append(class.Lines, CoberturaLine{Number: line.Number, Hits: line.Hits})
+		}
+		report.Packages[i].Classes = append(report.Packages[i].Classes, class)
+
+		counts := pkgLines[pkgName]
+		counts[0] += f.Covered()
+		counts[1] += len(f.Lines)
+		pkgLines[pkgName] = counts
+		report.LinesCovered += f.Covered()
+		report.LinesValid += len(f.Lines)
+	}
+
+	for i, pkg := range report.Packages {
+		counts := pkgLines[pkg.Name]
+		report.Packages[i].LineRate = lineRate(counts[0], counts[1])
+	}
+	report.LineRate = lineRate(report.LinesCovered, report.LinesValid)
+	return report, nil
+}
+
+func writeCobertura(out io.Writer, report Cobertura) error {
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+	doctype := `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">` + "\n"
+	if _, err := io.WriteString(out, doctype); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "\t")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(out, "\n")
+	return err
+}
+
+// relativeFilename returns the name of the file relative to the root of the
+// module.
+func relativeFilename(name string) string {
+	return path.Join(testjson.RelativePackagePath(path.Dir(name)), path.Base(name))
+}
+
+func lineRate(covered, total int) string {
+	if total == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(float64(covered)/float64(total), 'f', 4, 64)
+}
@@ -0,0 +1,240 @@
+/*Package coverprofile reads, merges, and writes the coverage profiles written
+by 'go test -coverprofile'.
+*/
+package coverprofile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Profile is a coverage profile.
+type Profile struct {
+	// Mode is the coverage mode, one of set, count, or atomic.
+	Mode   string
+	blocks map[string]*block
+}
+
+// block is the coverage of a single block of code. Blocks are indexed by the
+// file name and position of the block, which are stored as the key.
+type block struct {
+	numStmt int
+	count   int
+}
+
+// New returns an empty Profile.
+func New() *Profile {
+	return &Profile{blocks: make(map[string]*block)}
+}
+
+// Parse a coverage profile from r.
+func Parse(r io.Reader) (*Profile, error) {
+	profile := New()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "mode: ") {
+			mode := strings.TrimPrefix(line, "mode: ")
+			if profile.Mode != "" && profile.Mode != mode {
+				return nil, fmt.Errorf("coverage profile has more than one mode: %v, %v", profile.Mode, mode)
+			}
+			profile.Mode = mode
+			continue
+		}
+		key, b, err := parseBlock(line)
+		if err != nil {
+			return nil, err
+		}
+		profile.add(key, b)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read coverage profile: %v", err)
+	}
+	return profile, nil
+}
+
+// parseBlock parses a line in the format:
+//
+//	name.go:line.column,line.column numberOfStatements count
+func parseBlock(line string) (string, block, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return "", block{}, fmt.Errorf("invalid line in coverage profile: %v", line)
+	}
+	numStmt, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return "", block{}, fmt.Errorf("invalid number of statements in coverage profile: %v", line)
+	}
+	count, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return "", block{}, fmt.Errorf("invalid count in coverage profile: %v", line)
+	}
+	return fields[0], block{numStmt: numStmt, count: count}, nil
+}
+
+func (p *Profile) add(key string, b block) {
+	if p.blocks == nil {
+		p.blocks = make(map[string]*block)
+	}
+	existing, ok := p.blocks[key]
+	if !ok {
+		p.blocks[key] = &b
+		return
+	}
+	if p.Mode == "set" {
+		if b.count > existing.count {
+			existing.count = b.count
+		}
+		return
+	}
+	existing.count += b.count
+}
+
+// Merge the blocks from other into p. A block which is in both profiles is
+// covered if it is covered in either profile. In count and atomic mode the
+// counts are added.
+func (p *Profile) Merge(other *Profile) error {
+	switch {
+	case p.Mode == "":
+		p.Mode = other.Mode
+	case other.Mode != "" && other.Mode != p.Mode:
+		return fmt.Errorf("can not merge coverage profiles with different modes: %v, %v",
+			p.Mode, other.Mode)
+	}
+	for key, b := range other.blocks {
+		p.add(key, *b)
+	}
+	return nil
+}
+
+// Percent returns the percentage of statements which are covered. Returns 0
+// if the profile has no statements.
+func (p *Profile) Percent() float64 {
+	var total, covered int
+	for _, b := range p.blocks {
+		total += b.numStmt
+		if b.count > 0 {
+			covered += b.numStmt
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(covered) / float64(total) * 100
+}
+
+// Write the profile to out in the format used by 'go test -coverprofile'. The
+// blocks are sorted by file name and position.
+func (p *Profile) Write(out io.Writer) error {
+	mode := p.Mode
+	if mode == "" {
+		mode = "set"
+	}
+	keys := make([]string, 0, len(p.blocks))
+	for key := range p.blocks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	w := bufio.NewWriter(out)
+	fmt.Fprintf(w, "mode: %s\n", mode)
+	for _, key := range keys {
+		b := p.blocks[key]
+		fmt.Fprintf(w, "%s %d %d\n", key, b.numStmt, b.count)
+	}
+	return w.Flush()
+}
+
+// File is the coverage of the lines in a single source file.
+type File struct {
+	// Name of the file, as it appears in the profile. Usually the import path
+	// of the package followed by the file name.
+	Name string
+	// Lines in the file which contain statements, sorted by line number.
+	Lines []Line
+}
+
+// Line is the coverage of a single line in a source file.
+type Line struct {
+	Number int
+	// Hits is the number of times the line was run. In set mode it is 1 if
+	// the line was run, otherwise 0.
+	Hits int
+}
+
+// Covered returns the number of lines which were run at least once.
+func (f File) Covered() int {
+	var count int
+	for _, line := range f.Lines {
+		if line.Hits > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// Files returns the coverage of every line in every file in the profile, sorted
+// by file name. A line which is part of more than one block uses the largest
+// count from those blocks.
+func (p *Profile) Files() ([]File, error) {
+	lines := make(map[string]map[int]int)
+	for key, b := range p.blocks {
+		name, start, end, err := parseBlockKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if lines[name] == nil {
+			lines[name] = make(map[int]int)
+		}
+		for n := start; n <= end; n++ {
+			if hits, ok := lines[name][n]; !ok || b.count > hits {
+				lines[name][n] = b.count
+			}
+		}
+	}
+
+	files := make([]File, 0, len(lines))
+	for name, hits := range lines {
+		f := File{Name: name, Lines: make([]Line, 0, len(hits))}
+		for n, count := range hits {
+			f.Lines = append(f.Lines, Line{Number: n, Hits: count})
+		}
+		sort.Slice(f.Lines, func(i, j int) bool {
+			return f.Lines[i].Number < f.Lines[j].Number
+		})
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+// parseBlockKey parses the file name, start line, and end line from the key
+// of a block, in the format name.go:line.column,line.column.
+func parseBlockKey(key string) (string, int, int, error) {
+	colon := strings.LastIndex(key, ":")
+	if colon < 0 {
+		return "", 0, 0, fmt.Errorf("invalid block in coverage profile: %v", key)
+	}
+	positions := strings.Split(key[colon+1:], ",")
+	if len(positions) != 2 {
+		return "", 0, 0, fmt.Errorf("invalid block in coverage profile: %v", key)
+	}
+	start, err := strconv.Atoi(strings.SplitN(positions[0], ".", 2)[0])
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("invalid block in coverage profile: %v", key)
+	}
+	end, err := strconv.Atoi(strings.SplitN(positions[1], ".", 2)[0])
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("invalid block in coverage profile: %v", key)
+	}
+	return key[:colon], start, end, nil
+}
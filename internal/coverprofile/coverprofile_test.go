@@ -0,0 +1,119 @@
+package coverprofile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+)
+
+func TestMerge(t *testing.T) {
+	first, err := Parse(strings.NewReader(`mode: set
+example.com/pkg/a/a.go:3.20,5.2 2 1
+example.com/pkg/a/a.go:7.20,9.2 2 0
+example.com/pkg/a/a.go:11.20,13.2 1 0
+`))
+	assert.NilError(t, err)
+	assert.Equal(t, first.Percent(), 40.0)
+
+	second, err := Parse(strings.NewReader(`mode: set
+example.com/pkg/a/a.go:3.20,5.2 2 0
+example.com/pkg/a/a.go:7.20,9.2 2 1
+example.com/pkg/b/b.go:3.20,5.2 5 1
+`))
+	assert.NilError(t, err)
+
+	merged := New()
+	assert.NilError(t, merged.Merge(first))
+	assert.NilError(t, merged.Merge(second))
+	assert.Equal(t, merged.Percent(), 90.0)
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, merged.Write(out))
+	golden.Assert(t, out.String(), "merged.golden")
+}
+
+func TestMerge_CountMode(t *testing.T) {
+	profile, err := Parse(strings.NewReader(`mode: count
+example.com/pkg/a/a.go:3.20,5.2 2 3
+example.com/pkg/a/a.go:3.20,5.2 2 4
+`))
+	assert.NilError(t, err)
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, profile.Write(out))
+	assert.Equal(t, out.String(), "mode: count\nexample.com/pkg/a/a.go:3.20,5.2 2 7\n")
+}
+
+func TestMerge_DifferentModes(t *testing.T) {
+	set := &Profile{Mode: "set"}
+	count := &Profile{Mode: "count"}
+	err := set.Merge(count)
+	assert.Error(t, err, "can not merge coverage profiles with different modes: set, count")
+}
+
+func TestParse_InvalidLine(t *testing.T) {
+	_, err := Parse(strings.NewReader("mode: set\nexample.com/pkg/a/a.go:3.20,5.2 two 1\n"))
+	assert.ErrorContains(t, err, "invalid number of statements")
+}
+
+func TestFiles(t *testing.T) {
+	profile, err := Parse(strings.NewReader(`mode: count
+example.com/pkg/a/a.go:3.20,5.2 2 3
+example.com/pkg/a/a.go:5.2,6.10 1 0
+example.com/pkg/a/a.go:8.20,8.30 1 0
+`))
+	assert.NilError(t, err)
+
+	files, err := profile.Files()
+	assert.NilError(t, err)
+	expected := []File{
+		{
+			Name: "example.com/pkg/a/a.go",
+			Lines: []Line{
+				{Number: 3, Hits: 3},
+				{Number: 4, Hits: 3},
+				{Number: 5, Hits: 3},
+				{Number: 6, Hits: 0},
+				{Number: 8, Hits: 0},
+			},
+		},
+	}
+	assert.DeepEqual(t, files, expected)
+	assert.Equal(t, files[0].Covered(), 3)
+}
+
+func TestFiles_InvalidBlock(t *testing.T) {
+	profile, err := Parse(strings.NewReader("mode: set\nexample.com/pkg/a/a.go 2 1\n"))
+	assert.NilError(t, err)
+	_, err = profile.Files()
+	assert.Error(t, err, "invalid block in coverage profile: example.com/pkg/a/a.go")
+}
+
+func newReportProfile(t *testing.T) *Profile {
+	t.Helper()
+	profile, err := Parse(strings.NewReader(`mode: set
+gotest.tools/gotestsum/main.go:9.13,11.2 2 1
+gotest.tools/gotestsum/pkg/a/a.go:3.20,5.2 2 1
+gotest.tools/gotestsum/pkg/a/a.go:7.20,9.2 2 0
+gotest.tools/gotestsum/pkg/a/b.go:3.20,4.2 1 1
+`))
+	assert.NilError(t, err)
+	return profile
+}
+
+func TestWriteCobertura(t *testing.T) {
+	out := new(bytes.Buffer)
+	timestamp := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NilError(t, WriteCobertura(out, newReportProfile(t), timestamp))
+	golden.Assert(t, out.String(), "cobertura.golden")
+}
+
+func TestWriteLCOV(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NilError(t, WriteLCOV(out, newReportProfile(t)))
+	golden.Assert(t, out.String(), "lcov.golden")
+}
@@ -0,0 +1,32 @@
+package coverprofile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// WriteLCOV writes an LCOV tracefile of the profile to out. The source file of
+// every record is relative to the root of the module.
+func WriteLCOV(out io.Writer, profile *Profile) error {
+	files, err := profile.Files()
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(out)
+	fmt.Fprintln(w, "TN:")
+	for _, f := range files {
+		fmt.Fprintf(w, "SF:%s\n", relativeFilename(f.Name))
+		for _, line := range f.Lines {
+			fmt.Fprintf(w, "DA:%d,%d\n", line.Number, line.Hits)
+		}
+		fmt.Fprintf(w, "LF:%d\n", len(f.Lines))
+		fmt.Fprintf(w, "LH:%d\n", f.Covered())
+		fmt.Fprintln(w, "end_of_record")
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write LCOV: %v", err)
+	}
+	return nil
+}
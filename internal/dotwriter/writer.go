@@ -13,9 +13,13 @@ const ESC = 27
 
 // Writer buffers writes until Flush is called. Flush clears previously written
 // lines before writing new lines from the buffer.
+//
+// Lines written with WritePermanent are written above the lines from the
+// buffer, and are not cleared by the next Flush.
 type Writer struct {
 	out       io.Writer
 	buf       bytes.Buffer
+	permanent bytes.Buffer
 	lineCount int
 }
 
@@ -26,17 +30,40 @@ func New(out io.Writer) *Writer {
 
 // Flush the buffer, writing all buffered lines to out
 func (w *Writer) Flush() error {
-	if w.buf.Len() == 0 {
+	if w.buf.Len() == 0 && w.permanent.Len() == 0 {
 		return nil
 	}
 	w.clearLines(w.lineCount)
 	w.lineCount = bytes.Count(w.buf.Bytes(), []byte{'\n'})
-	_, err := w.out.Write(w.buf.Bytes())
+	w.permanent.Write(w.buf.Bytes())
+	_, err := w.out.Write(w.permanent.Bytes())
+	w.permanent.Reset()
 	w.buf.Reset()
 	return err
 }
 
+// Clear the lines written by the last Flush, and discard the buffer. Any
+// buffered permanent lines are written to out.
+func (w *Writer) Clear() error {
+	w.buf.Reset()
+	w.clearLines(w.lineCount)
+	w.lineCount = 0
+	if w.permanent.Len() == 0 {
+		return nil
+	}
+	_, err := w.out.Write(w.permanent.Bytes())
+	w.permanent.Reset()
+	return err
+}
+
 // Write saves buf to a buffer
 func (w *Writer) Write(buf []byte) (int, error) {
 	return w.buf.Write(buf)
 }
+
+// WritePermanent saves buf to a buffer of lines which are written before the
+// buffer on the next Flush. Permanent lines are never cleared, so buf should
+// end with a newline.
+func (w *Writer) WritePermanent(buf []byte) (int, error) {
+	return w.permanent.Write(buf)
+}
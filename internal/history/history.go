@@ -0,0 +1,153 @@
+/*Package history stores a compact record of the test cases from every test
+run, so that the results of many runs can be compared.
+
+Records are stored as line-delimited JSON. Each run appends a record for every
+test case to the end of the file.
+*/
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Record is the result of a single test case from a test run.
+type Record struct {
+	// Time when the test run started. All the records from the same run
+	// have the same time.
+	Time time.Time `json:"time"`
+	// Commit is the git commit that was checked out when the tests were run.
+	Commit  string `json:"commit,omitempty"`
+	Package string `json:"pkg"`
+	Test    string `json:"test"`
+	// Result is one of testjson.ActionPass, ActionFail, or ActionSkip.
+	Result testjson.Action `json:"result"`
+	// Elapsed time of the test case in seconds.
+	Elapsed float64 `json:"elapsed"`
+	// RunID is the TestCase.RunID, which is greater than 0 for re-runs.
+	RunID int `json:"run,omitempty"`
+}
+
+// ElapsedDuration returns Elapsed as a time.Duration.
+func (r Record) ElapsedDuration() time.Duration {
+	return time.Duration(r.Elapsed * float64(time.Second))
+}
+
+// NewRecords returns a Record for every test case in the execution.
+func NewRecords(exec *testjson.Execution, commit string) []Record {
+	var records []Record // nolint: prealloc
+	for _, name := range exec.Packages() {
+		pkg := exec.Package(name)
+		records = appendRecords(records, exec, commit, pkg.Passed, testjson.ActionPass)
+		records = appendRecords(records, exec, commit, pkg.Failed, testjson.ActionFail)
+		records = appendRecords(records, exec, commit, pkg.Skipped, testjson.ActionSkip)
+	}
+	return records
+}
+
+func appendRecords(
+	records []Record,
+	exec *testjson.Execution,
+	commit string,
+	cases []testjson.TestCase,
+	result testjson.Action,
+) []Record {
+	for _, tc := range cases {
+		records = append(records, Record{
+			Time:    exec.Started().UTC(),
+			Commit:  commit,
+			Package: tc.Package,
+			Test:    tc.Test.Name(),
+			Result:  result,
+			Elapsed: tc.Elapsed.Seconds(),
+			RunID:   tc.RunID,
+		})
+	}
+	return records
+}
+
+// Append a record of every test case in exec to the history file. The file
+// is created if it does not exist.
+func Append(filename string, exec *testjson.Execution) error {
+	fh, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer func() {
+		if err := fh.Close(); err != nil {
+			log.Errorf("Failed to close history file: %v", err)
+		}
+	}()
+	return Write(fh, NewRecords(exec, gitCommit()))
+}
+
+// Write records to out as line-delimited JSON.
+func Write(out io.Writer, records []Record) error {
+	w := bufio.NewWriter(out)
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("failed to write history: %v", err)
+		}
+	}
+	return w.Flush()
+}
+
+// Read all the records from in.
+func Read(in io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to parse history record: %v", err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %v", err)
+	}
+	return records, nil
+}
+
+// LastRunTotal returns the number of test cases in the most recent run in
+// records, not including re-runs. Returns 0 if there are no records.
+func LastRunTotal(records []Record) int {
+	var last time.Time
+	for _, record := range records {
+		if record.Time.After(last) {
+			last = record.Time
+		}
+	}
+	total := 0
+	for _, record := range records {
+		if record.Time.Equal(last) && record.RunID == 0 {
+			total++
+		}
+	}
+	return total
+}
+
+// gitCommit returns the commit hash of HEAD, or an empty string if the
+// working directory is not a git repository.
+func gitCommit() string {
+	log.Debugf("exec: git rev-parse HEAD")
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		log.Debugf("Failed to lookup git commit for history: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
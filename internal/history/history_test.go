@@ -0,0 +1,56 @@
+package history
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+)
+
+func TestNewRecords_RoundTrip(t *testing.T) {
+	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{
+		Stdout: strings.NewReader(`{"Package": "pkg", "Test": "TestOne", "Action": "run"}
+{"Package": "pkg", "Test": "TestOne", "Action": "pass", "Elapsed": 0.25}
+{"Package": "pkg", "Test": "TestTwo", "Action": "run"}
+{"Package": "pkg", "Test": "TestTwo", "Action": "fail", "Elapsed": 1}
+{"Package": "pkg", "Test": "TestThree", "Action": "run"}
+{"Package": "pkg", "Test": "TestThree", "Action": "skip"}
+{"Package": "pkg", "Action": "fail"}
+`),
+	})
+	assert.NilError(t, err)
+
+	records := NewRecords(exec, "abcdef")
+	started := exec.Started().UTC()
+	expected := []Record{
+		{Time: started, Commit: "abcdef", Package: "pkg", Test: "TestOne", Result: testjson.ActionPass, Elapsed: 0.25},
+		{Time: started, Commit: "abcdef", Package: "pkg", Test: "TestTwo", Result: testjson.ActionFail, Elapsed: 1},
+		{Time: started, Commit: "abcdef", Package: "pkg", Test: "TestThree", Result: testjson.ActionSkip},
+	}
+	assert.DeepEqual(t, records, expected)
+
+	buf := new(bytes.Buffer)
+	assert.NilError(t, Write(buf, records))
+	actual, err := Read(buf)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, actual, expected)
+	assert.Equal(t, actual[1].ElapsedDuration(), time.Second)
+}
+
+func TestLastRunTotal(t *testing.T) {
+	first := time.Date(2020, 10, 1, 10, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	records := []Record{
+		{Time: first, Package: "pkg", Test: "TestOne"},
+		{Time: second, Package: "pkg", Test: "TestOne"},
+		{Time: second, Package: "pkg", Test: "TestTwo"},
+		{Time: second, Package: "pkg", Test: "TestTwo", RunID: 1},
+		{Time: first, Package: "pkg", Test: "TestTwo"},
+		{Time: first, Package: "pkg", Test: "TestThree"},
+	}
+	assert.Equal(t, LastRunTotal(records), 2)
+	assert.Equal(t, LastRunTotal(nil), 0)
+}
@@ -0,0 +1,397 @@
+/*Package htmlreport creates a single page HTML report from a
+testjson.Execution. The report includes all styles inline, so that it can be
+viewed without network access.
+*/
+package htmlreport
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"gotest.tools/gotestsum/internal/reruns"
+	"gotest.tools/gotestsum/internal/testoutput"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// maxSlowest is the number of test cases in the list of slowest tests.
+const maxSlowest = 10
+
+type report struct {
+	Started     time.Time
+	Elapsed     string
+	Total       int
+	Failed      int
+	Skipped     int
+	Quarantined int
+	Errors      []string
+	Packages    []*treeNode
+	Slowest     []testReport
+	Reruns      []rerunReport
+}
+
+type packageReport struct {
+	Name        string
+	Result      testjson.Action
+	Total       int
+	Passed      int
+	Failed      int
+	Skipped     int
+	Quarantined int
+	Coverage    string
+	Cached      bool
+	Elapsed     string
+	// Failures does not include quarantined tests, which are in Quarantine.
+	Failures   []testReport
+	Quarantine []testReport
+	Skips      []testReport
+}
+
+// treeNode is a directory in the tree of packages. A node with a Package is
+// the directory of a package. The counts include every package in the
+// directory, and in all of its children.
+type treeNode struct {
+	Name        string
+	Package     *packageReport
+	Children    []*treeNode
+	Total       int
+	Passed      int
+	Failed      int
+	Skipped     int
+	Quarantined int
+}
+
+type testReport struct {
+	Package string
+	Name    string
+	Elapsed string
+	RunID   int
+	Output  string
+}
+
+type rerunReport struct {
+	Package  string
+	Name     string
+	Attempts []attemptReport
+}
+
+type attemptReport struct {
+	RunID   int
+	Result  testjson.Action
+	Elapsed string
+}
+
+// Write an HTML report of the execution to out.
+func Write(out io.Writer, exec *testjson.Execution) error {
+	return write(out, newReport(exec))
+}
+
+func write(out io.Writer, r report) error {
+	if err := reportTemplate.Execute(out, r); err != nil {
+		return fmt.Errorf("failed to write HTML report: %v", err)
+	}
+	return nil
+}
+
+func newReport(exec *testjson.Execution) report {
+	r := report{
+		Started:     exec.Started(),
+		Elapsed:     formatDuration(exec.Elapsed()),
+		Total:       exec.Total(),
+		Failed:      len(exec.Failed()),
+		Skipped:     len(exec.Skipped()),
+		Quarantined: len(exec.Quarantined()),
+		Errors:      exec.Errors(),
+	}
+
+	quarantined := make(map[string]map[int]bool)
+	for _, tc := range exec.Quarantined() {
+		if quarantined[tc.Package] == nil {
+			quarantined[tc.Package] = make(map[int]bool)
+		}
+		quarantined[tc.Package][tc.ID] = true
+	}
+
+	var all []testjson.TestCase
+	var packages []*packageReport
+	for _, name := range exec.Packages() {
+		pkg := exec.Package(name)
+		pr := &packageReport{
+			Name:     testjson.RelativePackagePath(name),
+			Result:   pkg.Result(),
+			Total:    pkg.Total,
+			Passed:   len(pkg.Passed),
+			Skipped:  len(pkg.Skipped),
+			Coverage: strings.TrimPrefix(pkg.Coverage(), "coverage: "),
+			Cached:   pkg.Cached(),
+			Elapsed:  formatDuration(pkg.Elapsed()),
+		}
+		for _, tc := range pkg.Failed {
+			if quarantined[name][tc.ID] {
+				pr.Quarantined++
+				continue
+			}
+			pr.Failed++
+		}
+		if pkg.TestMainFailed() {
+			pr.Failed++
+			tc := testjson.TestCase{Package: name, Test: "TestMain"}
+			pr.Failures = append(pr.Failures, newTestReport(tc, pkg.OutputLines(testjson.TestCase{})))
+		}
+		for _, tc := range testjson.FilterFailedUnique(pkg.Failed) {
+			tr := newTestReport(tc, exec.OutputLines(tc))
+			if quarantined[name][tc.ID] {
+				pr.Quarantine = append(pr.Quarantine, tr)
+				continue
+			}
+			pr.Failures = append(pr.Failures, tr)
+		}
+		for _, tc := range pkg.Skipped {
+			pr.Skips = append(pr.Skips, newTestReport(tc, exec.OutputLines(tc)))
+		}
+		packages = append(packages, pr)
+		r.Reruns = append(r.Reruns, newRerunReports(pkg)...)
+		all = append(all, pkg.TestCases()...)
+	}
+	r.Packages = newPackageTree(packages)
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Elapsed > all[j].Elapsed
+	})
+	for _, tc := range all {
+		if len(r.Slowest) == maxSlowest || tc.Elapsed <= 0 {
+			break
+		}
+		r.Slowest = append(r.Slowest, newTestReport(tc, nil))
+	}
+	return r
+}
+
+// newPackageTree returns the root nodes of a tree of the packages, with a node
+// for each directory in the package path. Directories with a single child, and
+// no package, are combined with their child.
+func newPackageTree(packages []*packageReport) []*treeNode {
+	root := &treeNode{}
+	for _, pr := range packages {
+		node := root
+		for _, part := range strings.Split(pr.Name, "/") {
+			node = node.child(part)
+		}
+		node.Package = pr
+	}
+	for _, child := range root.Children {
+		child.compact()
+		child.count()
+	}
+	return root.Children
+}
+
+func (n *treeNode) child(name string) *treeNode {
+	for _, child := range n.Children {
+		if child.Name == name {
+			return child
+		}
+	}
+	child := &treeNode{Name: name}
+	n.Children = append(n.Children, child)
+	return child
+}
+
+func (n *treeNode) compact() {
+	for n.Package == nil && len(n.Children) == 1 {
+		child := n.Children[0]
+		n.Name = n.Name + "/" + child.Name
+		n.Package, n.Children = child.Package, child.Children
+	}
+	for _, child := range n.Children {
+		child.compact()
+	}
+}
+
+func (n *treeNode) count() {
+	if pr := n.Package; pr != nil {
+		n.Total, n.Passed, n.Failed = pr.Total, pr.Passed, pr.Failed
+		n.Skipped, n.Quarantined = pr.Skipped, pr.Quarantined
+	}
+	for _, child := range n.Children {
+		child.count()
+		n.Total += child.Total
+		n.Passed += child.Passed
+		n.Failed += child.Failed
+		n.Skipped += child.Skipped
+		n.Quarantined += child.Quarantined
+	}
+}
+
+func newTestReport(tc testjson.TestCase, lines []string) testReport {
+	var output strings.Builder
+	for _, line := range lines {
+		if testoutput.IsFramingLine(line) {
+			continue
+		}
+		output.WriteString(line)
+	}
+	return testReport{
+		Package: testjson.RelativePackagePath(tc.Package),
+		Name:    tc.Test.Name(),
+		Elapsed: formatDuration(tc.Elapsed),
+		RunID:   tc.RunID,
+		Output:  output.String(),
+	}
+}
+
+// newRerunReports returns the attempts of every test in the package that was
+// re-run.
+func newRerunReports(pkg *testjson.Package) []rerunReport {
+	var result []rerunReport // nolint: prealloc
+	for _, test := range reruns.Reruns(pkg) {
+		rr := rerunReport{
+			Package: testjson.RelativePackagePath(test.Package),
+			Name:    test.Name.Name(),
+		}
+		for _, a := range test.Attempts {
+			rr.Attempts = append(rr.Attempts, attemptReport{
+				RunID:   a.RunID,
+				Result:  a.Result,
+				Elapsed: formatDuration(a.Elapsed),
+			})
+		}
+		result = append(result, rr)
+	}
+	return result
+}
+
+func formatDuration(d time.Duration) string {
+	return testjson.FormatDurationAsSeconds(d, 3)
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Test Report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: 0.25em 0.75em; border-bottom: 1px solid #e1e4e8; }
+td.num { text-align: right; }
+pre { background: #f6f8fa; padding: 0.75em; overflow-x: auto; }
+summary { cursor: pointer; }
+.pass { color: #22863a; }
+.fail { color: #cb2431; }
+.skip { color: #b08800; }
+.quarantined { color: #6a737d; }
+.totals span { margin-right: 1.5em; }
+.counts span { margin-left: 0.75em; }
+ul.tree, ul.tree ul { list-style: none; padding-left: 1.25em; }
+</style>
+</head>
+<body>
+<h1>Test Report</h1>
+<p class="totals">
+<span>{{.Total}} tests</span>
+<span class="fail">{{.Failed}} failed</span>
+<span class="skip">{{.Skipped}} skipped</span>
+{{- if .Quarantined}}
+<span>{{.Quarantined}} quarantined</span>
+{{- end}}
+<span>{{len .Errors}} errors</span>
+<span>{{.Elapsed}}</span>
+{{- if not .Started.IsZero}}
+<span>started {{.Started.UTC.Format "2006-01-02 15:04:05 MST"}}</span>
+{{- end}}
+</p>
+
+<h2>Packages</h2>
+<ul class="tree">
+{{- range .Packages}}
+{{template "node" .}}
+{{- end}}
+</ul>
+
+{{- if .Reruns}}
+<h2>Re-runs</h2>
+<table>
+<tr><th>Package</th><th>Test</th><th>Attempts</th></tr>
+{{- range .Reruns}}
+<tr>
+<td>{{.Package}}</td>
+<td>{{.Name}}</td>
+<td>{{range .Attempts}}<span class="{{.Result}}">{{.Result}} ({{.Elapsed}})</span> {{end}}</td>
+</tr>
+{{- end}}
+</table>
+{{- end}}
+
+{{- if .Slowest}}
+<h2>Slowest tests</h2>
+<table>
+<tr><th>Package</th><th>Test</th><th>Elapsed</th></tr>
+{{- range .Slowest}}
+<tr><td>{{.Package}}</td><td>{{.Name}}</td><td class="num">{{.Elapsed}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+
+{{- if .Errors}}
+<h2>Errors</h2>
+<pre>{{range .Errors}}{{.}}
+{{end}}</pre>
+{{- end}}
+</body>
+</html>
+{{- define "node"}}
+<li>
+<details{{if or .Failed .Quarantined (and .Package (eq .Package.Result "fail"))}} open{{end}}>
+<summary><span class="name">{{.Name}}</span>
+{{- with .Package}} <span class="{{.Result}}">{{.Result}}{{if .Cached}} (cached){{end}}</span>{{end}}
+<span class="counts">
+<span>{{.Total}} tests</span>
+<span class="pass">{{.Passed}} passed</span>
+<span class="fail">{{.Failed}} failed</span>
+<span class="skip">{{.Skipped}} skipped</span>
+{{- if .Quarantined}}
+<span class="quarantined">{{.Quarantined}} quarantined</span>
+{{- end}}
+{{- with .Package}}
+{{- if .Coverage}}
+<span>coverage {{.Coverage}}</span>
+{{- end}}
+<span>{{.Elapsed}}</span>
+{{- end}}
+</span>
+</summary>
+{{- with .Package}}
+{{- range .Failures}}
+<details>
+<summary class="fail">{{.Name}}{{if .RunID}} (re-run {{.RunID}}){{end}} ({{.Elapsed}})</summary>
+<pre>{{.Output}}</pre>
+</details>
+{{- end}}
+{{- range .Quarantine}}
+<details>
+<summary class="quarantined">{{.Name}}{{if .RunID}} (re-run {{.RunID}}){{end}} (quarantined) ({{.Elapsed}})</summary>
+<pre>{{.Output}}</pre>
+</details>
+{{- end}}
+{{- range .Skips}}
+<details>
+<summary class="skip">{{.Name}} (skipped)</summary>
+<pre>{{.Output}}</pre>
+</details>
+{{- end}}
+{{- end}}
+{{- if .Children}}
+<ul>
+{{- range .Children}}
+{{template "node" .}}
+{{- end}}
+</ul>
+{{- end}}
+</details>
+</li>
+{{- end}}
+`))
@@ -0,0 +1,51 @@
+package htmlreport
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+)
+
+func TestWrite(t *testing.T) {
+	exec := createExecution(t)
+
+	r := newReport(exec)
+	r.Started = time.Time{}
+	r.Elapsed = "0.000s"
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, write(out, r))
+	golden.Assert(t, out.String(), "report.golden.html")
+}
+
+func createExecution(t *testing.T) *testjson.Execution {
+	f, err := os.Open("../../testjson/testdata/go-test-json.out")
+	assert.NilError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	exec := testjson.NewExecution()
+	_, err = testjson.ScanTestOutput(testjson.ScanConfig{
+		Stdout:    f,
+		Stderr:    strings.NewReader("a build error <with> html\n"),
+		Execution: exec,
+	})
+	assert.NilError(t, err)
+
+	pkg := "github.com/gotestyourself/gotestyourself/testjson/internal/stub"
+	_, err = testjson.ScanTestOutput(testjson.ScanConfig{
+		RunID: 1,
+		Stdout: strings.NewReader(`{"Package": "` + pkg + `", "Test": "TestFailed", "Action": "run"}
+{"Package": "` + pkg + `", "Test": "TestFailed", "Action": "pass", "Elapsed": 0.2}
+{"Package": "` + pkg + `", "Action": "pass"}
+`),
+		Execution: exec,
+	})
+	assert.NilError(t, err)
+	return exec
+}
@@ -0,0 +1,95 @@
+// Package jsonfile opens files of test2json output for the tool commands.
+package jsonfile
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"gotest.tools/gotestsum/log"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Open returns a reader for the test2json output in filename. If filename is
+// empty, or '-', the output is read from stdin. Output compressed with gzip is
+// decompressed.
+func Open(filename string) (io.ReadCloser, error) {
+	var in io.ReadCloser
+	switch filename {
+	case "", "-":
+		in = ioutil.NopCloser(os.Stdin)
+	default:
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		in = f
+	}
+	reader, err := decompress(in)
+	if err != nil {
+		in.Close() // nolint: errcheck
+		return nil, err
+	}
+	return reader, nil
+}
+
+// gzipMagic is the header at the start of every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// decompress returns a reader which decompresses in, when in starts with the
+// gzip header. Otherwise the reader returns the bytes from in.
+func decompress(in io.ReadCloser) (io.ReadCloser, error) {
+	buf := bufio.NewReader(in)
+	header, err := buf.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.Equal(header, gzipMagic) {
+		return &readCloser{Reader: buf, closers: []io.Closer{in}}, nil
+	}
+	gz, err := gzip.NewReader(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gzip header: %v", err)
+	}
+	return &readCloser{Reader: gz, closers: []io.Closer{gz, in}}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+// Close every closer, and return the first error.
+func (r *readCloser) Close() error {
+	var result error
+	for _, closer := range r.closers {
+		if err := closer.Close(); err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// Scan scans the test2json output in filename using cfg. If filename is '-'
+// the output is read from stdin.
+func Scan(cfg testjson.ScanConfig, filename string) error {
+	in, err := Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read jsonfile: %v", err)
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			log.Errorf("Failed to close file %v: %v", filename, err)
+		}
+	}()
+
+	cfg.Stdout = in
+	if _, err := testjson.ScanTestOutput(cfg); err != nil {
+		return fmt.Errorf("failed to scan testjson from %v: %v", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package jsonfile
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestOpen(t *testing.T) {
+	const source = `{"Package": "pkg", "Action": "pass"}` + "\n"
+	compressed := new(bytes.Buffer)
+	gz := gzip.NewWriter(compressed)
+	_, err := gz.Write([]byte(source))
+	assert.NilError(t, err)
+	assert.NilError(t, gz.Close())
+
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("plain.json", source),
+		fs.WithFile("compressed.json.gz", compressed.String()),
+		fs.WithFile("empty.json", ""))
+	defer dir.Remove()
+
+	for _, tc := range []struct {
+		name     string
+		expected string
+	}{
+		{name: "plain.json", expected: source},
+		{name: "compressed.json.gz", expected: source},
+		{name: "empty.json", expected: ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			in, err := Open(dir.Join(tc.name))
+			assert.NilError(t, err)
+			raw, err := ioutil.ReadAll(in)
+			assert.NilError(t, err)
+			assert.NilError(t, in.Close())
+			assert.Equal(t, string(raw), tc.expected)
+		})
+	}
+}
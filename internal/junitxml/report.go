@@ -8,6 +8,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,13 +25,21 @@ type JUnitTestSuites struct {
 // JUnitTestSuite is a single JUnit test suite which may contain many
 // testcases.
 type JUnitTestSuite struct {
-	XMLName    xml.Name        `xml:"testsuite"`
-	Tests      int             `xml:"tests,attr"`
-	Failures   int             `xml:"failures,attr"`
-	Time       string          `xml:"time,attr"`
-	Name       string          `xml:"name,attr"`
-	Properties []JUnitProperty `xml:"properties>property,omitempty"`
+	XMLName    xml.Name         `xml:"testsuite"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr,omitempty"`
+	Skipped    int              `xml:"skipped,attr,omitempty"`
+	Time       string           `xml:"time,attr"`
+	Name       string           `xml:"name,attr"`
+	Timestamp  string           `xml:"timestamp,attr,omitempty"`
+	Hostname   string           `xml:"hostname,attr,omitempty"`
+	Properties *JUnitProperties `xml:"properties"`
 	TestCases  []JUnitTestCase
+	// Suites are nested test suites. Only used when Config.Subtests is
+	// SubtestsNested.
+	Suites    []JUnitTestSuite
+	SystemErr string `xml:"system-err,omitempty"`
 }
 
 // JUnitTestCase is a single test case with its result.
@@ -41,6 +50,14 @@ type JUnitTestCase struct {
 	Time        string            `xml:"time,attr"`
 	SkipMessage *JUnitSkipMessage `xml:"skipped,omitempty"`
 	Failure     *JUnitFailure     `xml:"failure,omitempty"`
+	Error       *JUnitFailure     `xml:"error,omitempty"`
+	// FlakyFailures are the failed attempts of a test case which passed when
+	// it was re-run. Only used when Config.GroupReruns is true.
+	FlakyFailures []JUnitRerunFailure `xml:"flakyFailure,omitempty"`
+	// RerunFailures are the failed attempts of a test case which continued to
+	// fail when it was re-run. Only used when Config.GroupReruns is true.
+	RerunFailures []JUnitRerunFailure `xml:"rerunFailure,omitempty"`
+	SystemOut     string              `xml:"system-out,omitempty"`
 }
 
 // JUnitSkipMessage contains the reason why a testcase was skipped.
@@ -48,23 +65,58 @@ type JUnitSkipMessage struct {
 	Message string `xml:"message,attr"`
 }
 
+// JUnitProperties is the properties element of a test suite. It is a pointer
+// in JUnitTestSuite so that the element is omitted from nested test suites,
+// which have no properties.
+type JUnitProperties struct {
+	Properties []JUnitProperty `xml:"property"`
+}
+
 // JUnitProperty represents a key/value pair used to define properties.
 type JUnitProperty struct {
 	Name  string `xml:"name,attr"`
 	Value string `xml:"value,attr"`
 }
 
-// JUnitFailure contains data related to a failed test.
+// JUnitFailure contains data related to a failed test. It is also used for the
+// error element, which reports a failure outside of a test, like a build
+// failure, or a TestMain which exited non-zero.
 type JUnitFailure struct {
 	Message  string `xml:"message,attr"`
 	Type     string `xml:"type,attr"`
 	Contents string `xml:",chardata"`
 }
 
+// JUnitRerunFailure contains data related to a failed attempt of a test case
+// that was re-run. It uses the format of the Maven Surefire plugin.
+type JUnitRerunFailure struct {
+	Message    string `xml:"message,attr"`
+	Type       string `xml:"type,attr"`
+	Time       string `xml:"time,attr"`
+	StackTrace string `xml:"stackTrace"`
+}
+
 // Config used to write a junit XML document.
 type Config struct {
 	FormatTestSuiteName     FormatFunc
 	FormatTestCaseClassname FormatFunc
+	// SystemOut adds the output of every test case to a system-out element.
+	// The output of passed tests is only available when the Execution was
+	// configured with SetKeepPassedOutput.
+	SystemOut bool
+	// GroupReruns reports every attempt of a test case, from re-runs, as a
+	// single testcase element. Earlier failed attempts are added as
+	// flakyFailure elements when the last attempt passed, or as rerunFailure
+	// elements when the last attempt failed.
+	GroupReruns bool
+	// Subtests configures how subtests are reported. Defaults to SubtestsFlat.
+	Subtests SubtestFormat
+	// Extended adds the errors, skipped, timestamp, and hostname attributes,
+	// and a system-err element with any build errors, to each testsuite. A
+	// TestMain which exited non-zero is reported as an error instead of a
+	// failure, and a package which failed to build is reported as a testsuite
+	// with an error.
+	Extended bool
 }
 
 // FormatFunc converts a string from one format into another.
@@ -81,23 +133,140 @@ func Write(out io.Writer, exec *testjson.Execution, cfg Config) error {
 func generate(exec *testjson.Execution, cfg Config) JUnitTestSuites {
 	cfg = configWithDefaults(cfg)
 	version := goVersion()
+	host := lookupHostname()
 	suites := JUnitTestSuites{}
+	pkgErrors := exec.ErrorsByPackage()
 
-	for _, pkgname := range exec.Packages() {
+	names := exec.Packages()
+	if cfg.Extended {
+		names = packageNames(exec, pkgErrors)
+	}
+	for _, pkgname := range names {
 		pkg := exec.Package(pkgname)
+		if pkg == nil {
+			suites.Suites = append(suites.Suites, buildFailedSuite(
+				pkgname, pkgErrors[pkgname], exec.Started(), host, version, cfg))
+			continue
+		}
+
+		started := pkg.Started()
+		if started.IsZero() {
+			started = exec.Started()
+		}
 		junitpkg := JUnitTestSuite{
 			Name:       cfg.FormatTestSuiteName(pkgname),
 			Tests:      pkg.Total,
 			Time:       formatDurationAsSeconds(pkg.Elapsed()),
+			Timestamp:  formatTimestamp(started),
+			Hostname:   host,
 			Properties: packageProperties(version),
-			TestCases:  packageTestCases(pkg, cfg.FormatTestCaseClassname),
+			TestCases:  packageTestCases(pkg, cfg),
 			Failures:   len(pkg.Failed),
+			Skipped:    len(pkg.Skipped),
+			SystemErr:  formatErrors(pkgErrors[pkgname]),
+		}
+		if cfg.GroupReruns {
+			junitpkg.Tests, junitpkg.Failures, junitpkg.Skipped = countTestCases(junitpkg.TestCases)
+		}
+		switch cfg.Subtests {
+		case SubtestsClassname:
+			junitpkg.TestCases = classnameSubtests(junitpkg.TestCases)
+		case SubtestsNested:
+			junitpkg.TestCases, junitpkg.Suites = nestSubtests(junitpkg.TestCases)
+		}
+		for _, tc := range junitpkg.TestCases {
+			if tc.Error != nil {
+				junitpkg.Errors++
+			}
+		}
+		if !cfg.Extended {
+			junitpkg = withoutExtended(junitpkg)
 		}
 		suites.Suites = append(suites.Suites, junitpkg)
 	}
 	return suites
 }
 
+// packageNames returns the sorted names of all the packages with test events
+// or build errors.
+func packageNames(exec *testjson.Execution, pkgErrors map[string][]string) []string {
+	names := exec.Packages()
+	for name := range pkgErrors {
+		if exec.Package(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// buildFailedSuite returns a test suite for a package which failed to build,
+// and has no test events.
+func buildFailedSuite(
+	pkgname string,
+	errors []string,
+	started time.Time,
+	host string,
+	version string,
+	cfg Config,
+) JUnitTestSuite {
+	jtc := newJUnitTestCase(testjson.TestCase{Test: "[build failed]"}, cfg.FormatTestCaseClassname)
+	jtc.Classname = cfg.FormatTestCaseClassname(pkgname)
+	jtc.Error = &JUnitFailure{
+		Message:  "Build failed",
+		Contents: formatErrors(errors),
+	}
+	return JUnitTestSuite{
+		Name:       cfg.FormatTestSuiteName(pkgname),
+		Tests:      1,
+		Errors:     1,
+		Time:       formatDurationAsSeconds(0),
+		Timestamp:  formatTimestamp(started),
+		Hostname:   host,
+		Properties: packageProperties(version),
+		TestCases:  []JUnitTestCase{jtc},
+		SystemErr:  formatErrors(errors),
+	}
+}
+
+// withoutExtended returns a copy of suite, and its nested suites, without the
+// attributes and elements which are only added when Config.Extended is true.
+func withoutExtended(suite JUnitTestSuite) JUnitTestSuite {
+	suite.Errors, suite.Skipped = 0, 0
+	suite.Timestamp, suite.Hostname, suite.SystemErr = "", "", ""
+	nested := make([]JUnitTestSuite, 0, len(suite.Suites))
+	for _, s := range suite.Suites {
+		nested = append(nested, withoutExtended(s))
+	}
+	if len(nested) > 0 {
+		suite.Suites = nested
+	}
+	return suite
+}
+
+func formatErrors(errors []string) string {
+	if len(errors) == 0 {
+		return ""
+	}
+	return strings.Join(errors, "\n") + "\n"
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05")
+}
+
+// hostname is a shim for testing
+var hostname = os.Hostname
+
+func lookupHostname() string {
+	host, err := hostname()
+	if err != nil {
+		log.Warnf("Failed to lookup hostname for junit xml: %v", err)
+		return ""
+	}
+	return host
+}
+
 func configWithDefaults(cfg Config) Config {
 	noop := func(v string) string {
 		return v
@@ -115,10 +284,10 @@ func formatDurationAsSeconds(d time.Duration) string {
 	return fmt.Sprintf("%f", d.Seconds())
 }
 
-func packageProperties(goVersion string) []JUnitProperty {
-	return []JUnitProperty{
+func packageProperties(goVersion string) *JUnitProperties {
+	return &JUnitProperties{Properties: []JUnitProperty{
 		{Name: "go.version", Value: goVersion},
-	}
+	}}
 }
 
 // goVersion returns the version as reported by the go binary in PATH. This
@@ -141,42 +310,74 @@ func goVersion() string {
 	return strings.TrimPrefix(strings.TrimSpace(string(out)), "go version ")
 }
 
-func packageTestCases(pkg *testjson.Package, formatClassname FormatFunc) []JUnitTestCase {
+func packageTestCases(pkg *testjson.Package, cfg Config) []JUnitTestCase {
 	cases := []JUnitTestCase{}
 
 	if pkg.TestMainFailed() {
-		jtc := newJUnitTestCase(testjson.TestCase{Test: "TestMain"}, formatClassname)
-		jtc.Failure = &JUnitFailure{
-			Message:  "Failed",
-			Contents: pkg.Output(0),
-		}
-		cases = append(cases, jtc)
+		cases = append(cases, newTestMainTestCase(pkg, cfg))
+	}
+	if cfg.GroupReruns {
+		return append(cases, groupedTestCases(pkg, cfg)...)
 	}
 
 	for _, tc := range pkg.Failed {
-		jtc := newJUnitTestCase(tc, formatClassname)
-		jtc.Failure = &JUnitFailure{
-			Message:  "Failed",
-			Contents: strings.Join(pkg.OutputLines(tc), ""),
-		}
-		cases = append(cases, jtc)
+		cases = append(cases, newFailedTestCase(pkg, tc, cfg))
 	}
-
 	for _, tc := range pkg.Skipped {
-		jtc := newJUnitTestCase(tc, formatClassname)
-		jtc.SkipMessage = &JUnitSkipMessage{
-			Message: strings.Join(pkg.OutputLines(tc), ""),
-		}
-		cases = append(cases, jtc)
+		cases = append(cases, newSkippedTestCase(pkg, tc, cfg))
 	}
-
 	for _, tc := range pkg.Passed {
-		jtc := newJUnitTestCase(tc, formatClassname)
-		cases = append(cases, jtc)
+		cases = append(cases, newPassedTestCase(pkg, tc, cfg))
 	}
 	return cases
 }
 
+func newTestMainTestCase(pkg *testjson.Package, cfg Config) JUnitTestCase {
+	jtc := newJUnitTestCase(testjson.TestCase{Test: "TestMain"}, cfg.FormatTestCaseClassname)
+	failure := &JUnitFailure{
+		Message:  "Failed",
+		Contents: pkg.Output(0),
+	}
+	if cfg.Extended {
+		jtc.Error = failure
+	} else {
+		jtc.Failure = failure
+	}
+	return jtc
+}
+
+func newFailedTestCase(pkg *testjson.Package, tc testjson.TestCase, cfg Config) JUnitTestCase {
+	jtc := newJUnitTestCase(tc, cfg.FormatTestCaseClassname)
+	jtc.Failure = &JUnitFailure{
+		Message:  "Failed",
+		Contents: strings.Join(pkg.OutputLines(tc), ""),
+	}
+	jtc.SystemOut = systemOut(pkg, tc, cfg)
+	return jtc
+}
+
+func newSkippedTestCase(pkg *testjson.Package, tc testjson.TestCase, cfg Config) JUnitTestCase {
+	jtc := newJUnitTestCase(tc, cfg.FormatTestCaseClassname)
+	jtc.SkipMessage = &JUnitSkipMessage{
+		Message: strings.Join(pkg.OutputLines(tc), ""),
+	}
+	jtc.SystemOut = systemOut(pkg, tc, cfg)
+	return jtc
+}
+
+func newPassedTestCase(pkg *testjson.Package, tc testjson.TestCase, cfg Config) JUnitTestCase {
+	jtc := newJUnitTestCase(tc, cfg.FormatTestCaseClassname)
+	jtc.SystemOut = systemOut(pkg, tc, cfg)
+	return jtc
+}
+
+func systemOut(pkg *testjson.Package, tc testjson.TestCase, cfg Config) string {
+	if !cfg.SystemOut {
+		return ""
+	}
+	return pkg.Output(tc.ID)
+}
+
 func newJUnitTestCase(tc testjson.TestCase, formatClassname FormatFunc) JUnitTestCase {
 	return JUnitTestCase{
 		Classname: formatClassname(tc.Package),
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"os"
 	"runtime"
+	"strings"
 	"testing"
 
 	"gotest.tools/gotestsum/testjson"
@@ -16,23 +18,63 @@ import (
 
 func TestWrite(t *testing.T) {
 	out := new(bytes.Buffer)
-	exec := createExecution(t)
+	exec := createExecution(t, testjson.NewExecution())
 
 	defer env.Patch(t, "GOVERSION", "go7.7.7")()
+	defer patchHostname("hostname")()
 	err := Write(out, exec, Config{})
 	assert.NilError(t, err)
-	golden.Assert(t, out.String(), "junitxml-report.golden")
+	golden.Assert(t, replaceExecStarted(out.String(), exec), "junitxml-report.golden")
 }
 
-func createExecution(t *testing.T) *testjson.Execution {
+func TestWrite_WithExtended(t *testing.T) {
+	out := new(bytes.Buffer)
+	exec := createExecution(t, testjson.NewExecution())
+
+	defer env.Patch(t, "GOVERSION", "go7.7.7")()
+	defer patchHostname("hostname")()
+	err := Write(out, exec, Config{Extended: true})
+	assert.NilError(t, err)
+	golden.Assert(t, replaceExecStarted(out.String(), exec), "junitxml-report-extended.golden")
+}
+
+func TestWrite_WithSystemOut(t *testing.T) {
+	out := new(bytes.Buffer)
+	exec := testjson.NewExecution()
+	exec.SetKeepPassedOutput(true)
+	exec = createExecution(t, exec)
+
+	defer env.Patch(t, "GOVERSION", "go7.7.7")()
+	defer patchHostname("hostname")()
+	err := Write(out, exec, Config{SystemOut: true})
+	assert.NilError(t, err)
+	golden.Assert(t, replaceExecStarted(out.String(), exec), "junitxml-report-system-out.golden")
+}
+
+func createExecution(t *testing.T, exec *testjson.Execution) *testjson.Execution {
 	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{
-		Stdout: readTestData(t, "out"),
-		Stderr: readTestData(t, "err"),
+		Stdout:    readTestData(t, "out"),
+		Stderr:    readTestData(t, "err"),
+		Execution: exec,
 	})
 	assert.NilError(t, err)
 	return exec
 }
 
+// replaceExecStarted replaces the timestamp of test suites which have no events,
+// and use the time the execution started.
+func replaceExecStarted(out string, exec *testjson.Execution) string {
+	started := `timestamp="` + formatTimestamp(exec.Started()) + `"`
+	return strings.Replace(out, started, `timestamp="STARTED"`, -1)
+}
+
+func patchHostname(name string) func() {
+	hostname = func() (string, error) {
+		return name, nil
+	}
+	return func() { hostname = os.Hostname }
+}
+
 func readTestData(t *testing.T, stream string) io.Reader {
 	raw, err := ioutil.ReadFile("../../testjson/testdata/go-test-json." + stream)
 	assert.NilError(t, err)
@@ -50,3 +92,82 @@ func TestGoVersion(t *testing.T) {
 		assert.Equal(t, goVersion(), expected)
 	})
 }
+
+func TestWrite_WithGroupReruns(t *testing.T) {
+	exec := testjson.NewExecution()
+	runs := []string{
+		`{"Package": "example.com/pkg", "Test": "TestFlaky", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestFlaky", "Action": "output", "Output": "flaky_test.go:10: first attempt failed\n"}
+{"Package": "example.com/pkg", "Test": "TestFlaky", "Action": "fail", "Elapsed": 0.1}
+{"Package": "example.com/pkg", "Test": "TestBroken", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestBroken", "Action": "output", "Output": "broken_test.go:20: failed 1\n"}
+{"Package": "example.com/pkg", "Test": "TestBroken", "Action": "fail", "Elapsed": 0.2}
+{"Package": "example.com/pkg", "Test": "TestPassed", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestPassed", "Action": "pass", "Elapsed": 0.3}
+{"Package": "example.com/pkg", "Action": "fail"}
+`,
+		`{"Package": "example.com/pkg", "Test": "TestFlaky", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestFlaky", "Action": "pass", "Elapsed": 0.4}
+{"Package": "example.com/pkg", "Test": "TestBroken", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestBroken", "Action": "output", "Output": "broken_test.go:20: failed 2\n"}
+{"Package": "example.com/pkg", "Test": "TestBroken", "Action": "fail", "Elapsed": 0.5}
+{"Package": "example.com/pkg", "Action": "fail"}
+`,
+		`{"Package": "example.com/pkg", "Test": "TestBroken", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestBroken", "Action": "output", "Output": "broken_test.go:20: failed 3\n"}
+{"Package": "example.com/pkg", "Test": "TestBroken", "Action": "fail", "Elapsed": 0.6}
+{"Package": "example.com/pkg", "Action": "fail"}
+`,
+	}
+	for i, run := range runs {
+		_, err := testjson.ScanTestOutput(testjson.ScanConfig{
+			RunID:     i,
+			Stdout:    strings.NewReader(run),
+			Execution: exec,
+		})
+		assert.NilError(t, err)
+	}
+
+	out := new(bytes.Buffer)
+	defer env.Patch(t, "GOVERSION", "go7.7.7")()
+	defer patchHostname("hostname")()
+	err := Write(out, exec, Config{GroupReruns: true})
+	assert.NilError(t, err)
+	golden.Assert(t, replaceExecStarted(out.String(), exec), "junitxml-report-group-reruns.golden")
+}
+
+func TestWrite_WithSubtests(t *testing.T) {
+	source := `{"Package": "example.com/pkg", "Test": "TestNested", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestNested/a", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestNested/a", "Action": "pass", "Elapsed": 0.1}
+{"Package": "example.com/pkg", "Test": "TestNested/b", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestNested/b/c", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestNested/b/c", "Action": "output", "Output": "nested_test.go:10: failed\n"}
+{"Package": "example.com/pkg", "Test": "TestNested/b/c", "Action": "fail", "Elapsed": 0.2}
+{"Package": "example.com/pkg", "Test": "TestNested/b/d", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestNested/b/d", "Action": "skip", "Elapsed": 0}
+{"Package": "example.com/pkg", "Test": "TestNested/b", "Action": "fail", "Elapsed": 0.3}
+{"Package": "example.com/pkg", "Test": "TestNested", "Action": "fail", "Elapsed": 0.5}
+{"Package": "example.com/pkg", "Test": "TestPassed", "Action": "run"}
+{"Package": "example.com/pkg", "Test": "TestPassed", "Action": "pass", "Elapsed": 0.1}
+{"Package": "example.com/pkg", "Action": "fail", "Elapsed": 0.7}
+`
+	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{
+		Stdout: strings.NewReader(source),
+	})
+	assert.NilError(t, err)
+
+	defer env.Patch(t, "GOVERSION", "go7.7.7")()
+	defer patchHostname("hostname")()
+
+	for _, format := range []SubtestFormat{SubtestsNested, SubtestsClassname} {
+		format := format
+		t.Run(string(format), func(t *testing.T) {
+			out := new(bytes.Buffer)
+			err := Write(out, exec, Config{Subtests: format})
+			assert.NilError(t, err)
+			golden.Assert(t, replaceExecStarted(out.String(), exec),
+				"junitxml-report-subtests-"+string(format)+".golden")
+		})
+	}
+}
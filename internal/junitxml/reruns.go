@@ -0,0 +1,88 @@
+package junitxml
+
+import (
+	"gotest.tools/gotestsum/internal/reruns"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// groupedTestCases returns a JUnitTestCase for every test in the package, with
+// all the attempts of a test combined into a single JUnitTestCase.
+func groupedTestCases(pkg *testjson.Package, cfg Config) []JUnitTestCase {
+	tests := reruns.Group(pkg)
+	cases := make([]JUnitTestCase, 0, len(tests))
+	for _, test := range tests {
+		attempts := make([]JUnitTestCase, 0, len(test.Attempts))
+		for _, a := range test.Attempts {
+			switch a.Result {
+			case testjson.ActionFail:
+				attempts = append(attempts, newFailedTestCase(pkg, a.TestCase, cfg))
+			case testjson.ActionSkip:
+				attempts = append(attempts, newSkippedTestCase(pkg, a.TestCase, cfg))
+			default:
+				attempts = append(attempts, newPassedTestCase(pkg, a.TestCase, cfg))
+			}
+		}
+		cases = append(cases, groupAttempts(attempts))
+	}
+	return cases
+}
+
+// groupAttempts combines every attempt of a test case into a single
+// JUnitTestCase. The attempts must be in the order they were run.
+//
+// If the last attempt passed, the failed attempts are added as FlakyFailures.
+// If the last attempt failed, the first failure is kept as the Failure, and
+// subsequent failures are added as RerunFailures.
+func groupAttempts(attempts []JUnitTestCase) JUnitTestCase {
+	last := attempts[len(attempts)-1]
+	if len(attempts) == 1 {
+		return last
+	}
+
+	var failures []JUnitTestCase
+	for _, attempt := range attempts {
+		if attempt.Failure != nil {
+			failures = append(failures, attempt)
+		}
+	}
+
+	result := last
+	switch {
+	case last.Failure == nil:
+		for _, attempt := range failures {
+			result.FlakyFailures = append(result.FlakyFailures, newRerunFailure(attempt))
+		}
+	default:
+		result.Failure = failures[0].Failure
+		for _, attempt := range failures[1:] {
+			result.RerunFailures = append(result.RerunFailures, newRerunFailure(attempt))
+		}
+	}
+	return result
+}
+
+func newRerunFailure(tc JUnitTestCase) JUnitRerunFailure {
+	return JUnitRerunFailure{
+		Message:    tc.Failure.Message,
+		Type:       tc.Failure.Type,
+		Time:       tc.Time,
+		StackTrace: tc.Failure.Contents,
+	}
+}
+
+// countTestCases returns the number of tests, failures, and skipped tests.
+// Errors are not counted as tests.
+func countTestCases(cases []JUnitTestCase) (tests, failures, skipped int) {
+	for _, tc := range cases {
+		switch {
+		case tc.Error != nil:
+			continue
+		case tc.Failure != nil:
+			failures++
+		case tc.SkipMessage != nil:
+			skipped++
+		}
+		tests++
+	}
+	return tests, failures, skipped
+}
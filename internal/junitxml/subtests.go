@@ -0,0 +1,119 @@
+package junitxml
+
+import (
+	"strings"
+
+	"gotest.tools/gotestsum/testjson"
+)
+
+// SubtestFormat configures how subtests, from t.Run, are reported.
+type SubtestFormat string
+
+const (
+	// SubtestsFlat reports every subtest as a testcase in the package
+	// testsuite, with the full test name as the name. This is the default.
+	SubtestsFlat SubtestFormat = "flat"
+	// SubtestsNested reports every test which has subtests as a nested
+	// testsuite. The testsuite contains a testcase with the result of the
+	// test itself, followed by its subtests.
+	SubtestsNested SubtestFormat = "nested"
+	// SubtestsClassname reports every subtest as a testcase with the root
+	// test name appended to the classname, and the rest of the test name as
+	// the name.
+	SubtestsClassname SubtestFormat = "classname"
+)
+
+// classnameSubtests moves the root test name of subtests into the classname.
+func classnameSubtests(cases []JUnitTestCase) []JUnitTestCase {
+	for i, tc := range cases {
+		root, sub := testjson.TestName(tc.Name).Split()
+		if sub == "" {
+			continue
+		}
+		cases[i].Classname = tc.Classname + "." + root
+		cases[i].Name = sub
+	}
+	return cases
+}
+
+type subtestNode struct {
+	cases    []JUnitTestCase
+	children []string
+}
+
+// nestSubtests returns the test cases which have no subtests, and a testsuite
+// for each test which has subtests. The order of the cases is preserved within
+// each testsuite.
+func nestSubtests(cases []JUnitTestCase) ([]JUnitTestCase, []JUnitTestSuite) {
+	nodes := make(map[string]*subtestNode)
+	var order []string
+	for _, tc := range cases {
+		node, ok := nodes[tc.Name]
+		if !ok {
+			node = &subtestNode{}
+			nodes[tc.Name] = node
+			order = append(order, tc.Name)
+		}
+		node.cases = append(node.cases, tc)
+	}
+
+	var roots []string
+	for _, name := range order {
+		parent := parentTestName(name, nodes)
+		if parent == "" {
+			roots = append(roots, name)
+			continue
+		}
+		nodes[parent].children = append(nodes[parent].children, name)
+	}
+
+	var topCases []JUnitTestCase
+	var suites []JUnitTestSuite
+	for _, name := range roots {
+		if len(nodes[name].children) == 0 {
+			topCases = append(topCases, nodes[name].cases...)
+			continue
+		}
+		suites = append(suites, newSubtestSuite(name, nodes))
+	}
+	return topCases, suites
+}
+
+// parentTestName returns the name of the closest parent test of name which has
+// a node, or an empty string if there is no parent.
+func parentTestName(name string, nodes map[string]*subtestNode) string {
+	for {
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			return ""
+		}
+		name = name[:i]
+		if _, ok := nodes[name]; ok {
+			return name
+		}
+	}
+}
+
+func newSubtestSuite(name string, nodes map[string]*subtestNode) JUnitTestSuite {
+	node := nodes[name]
+	suite := JUnitTestSuite{
+		Name: name,
+		Time: node.cases[len(node.cases)-1].Time,
+	}
+	suite.TestCases = append(suite.TestCases, node.cases...)
+	for _, child := range node.children {
+		if len(nodes[child].children) == 0 {
+			suite.TestCases = append(suite.TestCases, nodes[child].cases...)
+			continue
+		}
+		suite.Suites = append(suite.Suites, newSubtestSuite(child, nodes))
+	}
+
+	suite.Tests, suite.Failures, suite.Skipped = countTestCases(suite.TestCases)
+	for _, nested := range suite.Suites {
+		suite.Tests += nested.Tests
+		suite.Failures += nested.Failures
+		suite.Skipped += nested.Skipped
+	}
+	return suite
+}
@@ -0,0 +1,278 @@
+/*Package markdown creates a GitHub flavored markdown report from a
+testjson.Execution, for use in pull request comments or job summaries.
+*/
+package markdown
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gotest.tools/gotestsum/internal/reruns"
+	"gotest.tools/gotestsum/internal/testoutput"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// DefaultMaxSize is the maximum size of a GitHub comment, less a small amount
+// of space for any text added by the tool which posts the comment.
+const DefaultMaxSize = 65000
+
+// Config used to write a report.
+type Config struct {
+	// MaxSize is the maximum size of the report in bytes. The output of
+	// failed tests is truncated so that the report fits within the limit.
+	// Defaults to DefaultMaxSize.
+	MaxSize int
+	// Packages adds a table with the results of every package to the report.
+	Packages bool
+}
+
+// truncatedNote is written in place of lines removed from the output of a test.
+const truncatedNote = "... %d lines truncated ...\n"
+
+// sectionShare limits the size of each section other than the failed tests to
+// 1/sectionShare of the report, so that most of the report is left for the
+// output of failed tests.
+const sectionShare = 8
+
+// Write a markdown report of the execution to out.
+func Write(out io.Writer, exec *testjson.Execution, cfg Config) error {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultMaxSize
+	}
+
+	buf := new(strings.Builder)
+	writeTotals(buf, exec)
+	budget := cfg.MaxSize - buf.Len()
+	limit := budget / sectionShare
+
+	var packages strings.Builder
+	if cfg.Packages {
+		writePackages(&packages, exec, limit)
+	}
+	var footer strings.Builder
+	writeFlaky(&footer, exec, limit)
+	writeSkipped(&footer, exec, limit)
+	writeErrors(&footer, exec, limit)
+
+	buf.WriteString(packages.String())
+	writeFailed(buf, exec, budget-packages.Len()-footer.Len())
+	buf.WriteString(footer.String())
+
+	_, err := io.WriteString(out, buf.String())
+	return err
+}
+
+func writeTotals(buf *strings.Builder, exec *testjson.Execution) {
+	failed := len(failedTests(exec))
+	errors := len(exec.Errors())
+	status := "✅ Passed"
+	switch {
+	case errors > 0:
+		status = "⚠️ Errored"
+	case failed > 0:
+		status = "❌ Failed"
+	}
+	passed := 0
+	for _, name := range exec.Packages() {
+		passed += len(exec.Package(name).Passed)
+	}
+	fmt.Fprintf(buf, "### %s\n\n", status)
+	buf.WriteString("| Tests | Passed | Failed | Skipped | Errors |\n")
+	buf.WriteString("| --: | --: | --: | --: | --: |\n")
+	fmt.Fprintf(buf, "| %d | %d | %d | %d | %d |\n",
+		exec.Total(), passed, failed, len(exec.Skipped()), errors)
+}
+
+// writeList writes the heading followed by as many of the lines as fit within
+// size bytes. If some lines do not fit, a note with the number of omitted
+// lines is written in their place. Nothing is written if not even the note
+// fits.
+func writeList(buf *strings.Builder, heading string, lines []string, size int, omittedFormat string) {
+	if len(lines) == 0 {
+		return
+	}
+	size -= len(heading)
+	if len(fmt.Sprintf(omittedFormat, len(lines))) > size {
+		return
+	}
+
+	buf.WriteString(heading)
+	for i, line := range lines {
+		remaining := len(lines) - i - 1
+		need := len(line)
+		if remaining > 0 {
+			// leave space for the note about the lines after this one
+			need += len(fmt.Sprintf(omittedFormat, remaining))
+		}
+		if need > size {
+			fmt.Fprintf(buf, omittedFormat, len(lines)-i)
+			return
+		}
+		buf.WriteString(line)
+		size -= len(line)
+	}
+}
+
+func writePackages(buf *strings.Builder, exec *testjson.Execution, size int) {
+	var rows []string // nolint: prealloc
+	for _, name := range exec.Packages() {
+		pkg := exec.Package(name)
+		rows = append(rows, fmt.Sprintf("| `%s` | %s | %d | %d | %d | %s |\n",
+			testjson.RelativePackagePath(name),
+			pkg.Result(),
+			pkg.Total,
+			len(pkg.Failed),
+			len(pkg.Skipped),
+			testjson.FormatDurationAsSeconds(pkg.Elapsed(), 2)))
+	}
+	const heading = "\n| Package | Result | Tests | Failed | Skipped | Elapsed |\n" +
+		"| --- | --- | --: | --: | --: | --: |\n"
+	writeList(buf, heading, rows, size, "\n%d more packages are not shown.\n")
+}
+
+// writeFailed writes a collapsible section for each failed test. The output of
+// each test is truncated so that all the sections fit within budget bytes. If
+// there is not enough space for a section the remaining tests are omitted.
+func writeFailed(buf *strings.Builder, exec *testjson.Execution, budget int) {
+	failed := testjson.FilterFailedUnique(failedTests(exec))
+	if len(failed) == 0 {
+		return
+	}
+	heading := "\n#### Failed tests\n"
+	buf.WriteString(heading)
+	budget -= len(heading)
+
+	for i, tc := range failed {
+		name := tc.Test.Name()
+		if name == "" {
+			name = "TestMain"
+		}
+		header := fmt.Sprintf("\n<details><summary>%s.%s</summary>\n\n```\n",
+			testjson.RelativePackagePath(tc.Package), name)
+		const trailer = "```\n\n</details>\n"
+
+		remaining := len(failed) - i
+		omitted := fmt.Sprintf("\n%d more failed tests are not shown.\n", remaining)
+		available := (budget-len(omitted))/remaining - len(header) - len(trailer)
+		if available < len(truncatedNote) {
+			buf.WriteString(omitted)
+			return
+		}
+
+		section := header + truncate(outputLines(exec, tc), available) + trailer
+		buf.WriteString(section)
+		budget -= len(section)
+	}
+}
+
+// failedTests returns the failed tests from exec, except for those tests which
+// passed when they were re-run.
+func failedTests(exec *testjson.Execution) []testjson.TestCase {
+	flaky := make(map[string]map[testjson.TestName]bool)
+	for _, t := range flakyTests(exec) {
+		if flaky[t.Package] == nil {
+			flaky[t.Package] = make(map[testjson.TestName]bool)
+		}
+		flaky[t.Package][t.Name] = true
+	}
+
+	var result []testjson.TestCase
+	for _, tc := range exec.Failed() {
+		if !flaky[tc.Package][tc.Test] {
+			result = append(result, tc)
+		}
+	}
+	return result
+}
+
+// flakyTests returns the tests which failed, and then passed when they were
+// re-run.
+func flakyTests(exec *testjson.Execution) []reruns.Test {
+	var result []reruns.Test
+	for _, name := range exec.Packages() {
+		for _, t := range reruns.Reruns(exec.Package(name)) {
+			if t.Last().Result == testjson.ActionPass && t.Failures() > 0 {
+				result = append(result, t)
+			}
+		}
+	}
+	return result
+}
+
+func outputLines(exec *testjson.Execution, tc testjson.TestCase) []string {
+	var lines []string
+	for _, line := range exec.OutputLines(tc) {
+		if testoutput.IsFramingLine(line) {
+			continue
+		}
+		// prevent the output from closing the code block
+		line = strings.Replace(line, "```", "` ` `", -1)
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// truncate the lines to fit in size bytes. The last lines are kept, because
+// the failure message is usually at the end of the output.
+func truncate(lines []string, size int) string {
+	total := 0
+	for _, line := range lines {
+		total += len(line)
+	}
+	if total <= size {
+		return strings.Join(lines, "")
+	}
+
+	size -= len(fmt.Sprintf(truncatedNote, len(lines)))
+	var kept int
+	var result []string
+	for i := len(lines) - 1; i >= 0; i-- {
+		if kept+len(lines[i]) > size {
+			break
+		}
+		kept += len(lines[i])
+		result = append([]string{lines[i]}, result...)
+	}
+	note := fmt.Sprintf(truncatedNote, len(lines)-len(result))
+	return note + strings.Join(result, "")
+}
+
+func writeFlaky(buf *strings.Builder, exec *testjson.Execution, size int) {
+	var lines []string // nolint: prealloc
+	for _, t := range flakyTests(exec) {
+		lines = append(lines, fmt.Sprintf("- `%s.%s` (%d attempts)\n",
+			testjson.RelativePackagePath(t.Package), t.Name, len(t.Attempts)))
+	}
+	writeList(buf, "\n#### Passed on re-run\n\n", lines, size,
+		"- %d more tests are not shown.\n")
+}
+
+func writeSkipped(buf *strings.Builder, exec *testjson.Execution, size int) {
+	var lines []string // nolint: prealloc
+	for _, tc := range exec.Skipped() {
+		lines = append(lines, fmt.Sprintf("- `%s.%s`\n",
+			testjson.RelativePackagePath(tc.Package), tc.Test))
+	}
+	writeList(buf, "\n#### Skipped tests\n\n", lines, size,
+		"- %d more tests are not shown.\n")
+}
+
+// writeErrors writes the errors in a code block. The errors are truncated to
+// fit within size bytes.
+func writeErrors(buf *strings.Builder, exec *testjson.Execution, size int) {
+	errors := exec.Errors()
+	if len(errors) == 0 {
+		return
+	}
+	lines := make([]string, 0, len(errors))
+	for _, line := range errors {
+		lines = append(lines, strings.Replace(line, "```", "` ` `", -1)+"\n")
+	}
+	const header, trailer = "\n#### Errors\n\n```\n", "```\n"
+	available := size - len(header) - len(trailer)
+	if available < len(truncatedNote) {
+		return
+	}
+	buf.WriteString(header + truncate(lines, available) + trailer)
+}
@@ -0,0 +1,95 @@
+package markdown
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+)
+
+func TestWrite(t *testing.T) {
+	out := new(bytes.Buffer)
+	exec := createExecution(t)
+
+	assert.NilError(t, Write(out, exec, Config{}))
+	golden.Assert(t, out.String(), "report.golden.md")
+}
+
+func TestWrite_WithMaxSize(t *testing.T) {
+	out := new(bytes.Buffer)
+	exec := createExecution(t)
+
+	cfg := Config{MaxSize: 1500}
+	assert.NilError(t, Write(out, exec, cfg))
+	assert.Assert(t, out.Len() <= cfg.MaxSize, "size %d", out.Len())
+	golden.Assert(t, out.String(), "report-truncated.golden.md")
+}
+
+func TestWrite_WithMaxSizeAndPackages(t *testing.T) {
+	exec := createExecution(t)
+
+	for _, size := range []int{400, 800, 1200} {
+		out := new(bytes.Buffer)
+		cfg := Config{MaxSize: size, Packages: true}
+		assert.NilError(t, Write(out, exec, cfg))
+		assert.Assert(t, out.Len() <= cfg.MaxSize, "size %d, max %d", out.Len(), size)
+	}
+}
+
+func TestWriteList(t *testing.T) {
+	lines := []string{"- one\n", "- two\n", "- three\n"}
+	const heading, omitted = "#### Items\n", "- %d more\n"
+
+	t.Run("fits", func(t *testing.T) {
+		buf := new(strings.Builder)
+		writeList(buf, heading, lines, 100, omitted)
+		assert.Equal(t, buf.String(), "#### Items\n- one\n- two\n- three\n")
+	})
+	t.Run("omits the last lines", func(t *testing.T) {
+		buf := new(strings.Builder)
+		writeList(buf, heading, lines, 30, omitted)
+		assert.Equal(t, buf.String(), "#### Items\n- one\n- 2 more\n")
+	})
+	t.Run("nothing fits", func(t *testing.T) {
+		buf := new(strings.Builder)
+		writeList(buf, heading, lines, 15, omitted)
+		assert.Equal(t, buf.String(), "")
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	lines := []string{
+		"the first line of output\n",
+		"the second line of output\n",
+		"the third line of output\n",
+		"the last line of output\n",
+	}
+	t.Run("fits", func(t *testing.T) {
+		assert.Equal(t, truncate(lines, 200), strings.Join(lines, ""))
+	})
+	t.Run("keeps the last lines", func(t *testing.T) {
+		size := len(truncatedNote) + len(lines[2]) + len(lines[3])
+		expected := "... 2 lines truncated ...\n" + lines[2] + lines[3]
+		assert.Equal(t, truncate(lines, size), expected)
+	})
+}
+
+func createExecution(t *testing.T) *testjson.Execution {
+	exec, err := testjson.ScanTestOutput(testjson.ScanConfig{
+		Stdout: readTestData(t, "out"),
+		Stderr: readTestData(t, "err"),
+	})
+	assert.NilError(t, err)
+	return exec
+}
+
+func readTestData(t *testing.T, stream string) io.Reader {
+	raw, err := ioutil.ReadFile("../../testjson/testdata/go-test-json." + stream)
+	assert.NilError(t, err)
+	return bytes.NewReader(raw)
+}
@@ -0,0 +1,92 @@
+/*Package reruns groups every attempt of a test case, so that reports can show
+the tests which were re-run by --rerun-fails.
+*/
+package reruns
+
+import (
+	"sort"
+
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Attempt is a single run of a test case.
+type Attempt struct {
+	testjson.TestCase
+	// Result is one of testjson.ActionPass, ActionFail, or ActionSkip.
+	Result testjson.Action
+}
+
+// Test is every attempt of a test case, in the order they were run.
+type Test struct {
+	Package  string
+	Name     testjson.TestName
+	Attempts []Attempt
+}
+
+// Rerun returns true if any attempt of the test was from a re-run.
+func (t Test) Rerun() bool {
+	for _, a := range t.Attempts {
+		if a.RunID > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Last returns the most recent attempt of the test.
+func (t Test) Last() Attempt {
+	return t.Attempts[len(t.Attempts)-1]
+}
+
+// Failures returns the number of attempts which failed.
+func (t Test) Failures() int {
+	count := 0
+	for _, a := range t.Attempts {
+		if a.Result == testjson.ActionFail {
+			count++
+		}
+	}
+	return count
+}
+
+// Group returns every test case in the package, grouped by name. The tests are
+// sorted by the order of their first attempt.
+func Group(pkg *testjson.Package) []Test {
+	var attempts []Attempt // nolint: prealloc
+	for _, tc := range pkg.Passed {
+		attempts = append(attempts, Attempt{TestCase: tc, Result: testjson.ActionPass})
+	}
+	for _, tc := range pkg.Failed {
+		attempts = append(attempts, Attempt{TestCase: tc, Result: testjson.ActionFail})
+	}
+	for _, tc := range pkg.Skipped {
+		attempts = append(attempts, Attempt{TestCase: tc, Result: testjson.ActionSkip})
+	}
+	sort.Slice(attempts, func(i, j int) bool {
+		return attempts[i].ID < attempts[j].ID
+	})
+
+	var tests []Test
+	index := make(map[testjson.TestName]int)
+	for _, a := range attempts {
+		i, ok := index[a.Test]
+		if !ok {
+			i = len(tests)
+			index[a.Test] = i
+			tests = append(tests, Test{Package: a.Package, Name: a.Test})
+		}
+		tests[i].Attempts = append(tests[i].Attempts, a)
+	}
+	return tests
+}
+
+// Reruns returns the tests in the package which were re-run.
+func Reruns(pkg *testjson.Package) []Test {
+	var result []Test
+	for _, t := range Group(pkg) {
+		if t.Rerun() {
+			result = append(result, t)
+		}
+	}
+	return result
+}
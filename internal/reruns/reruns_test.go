@@ -0,0 +1,66 @@
+package reruns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+)
+
+func TestGroup(t *testing.T) {
+	exec := testjson.NewExecution()
+	_, err := testjson.ScanTestOutput(testjson.ScanConfig{
+		Stdout: strings.NewReader(`{"Package": "pkg", "Test": "TestFlaky", "Action": "run"}
+{"Package": "pkg", "Test": "TestFlaky", "Action": "fail", "Elapsed": 0.1}
+{"Package": "pkg", "Test": "TestOnce", "Action": "run"}
+{"Package": "pkg", "Test": "TestOnce", "Action": "pass", "Elapsed": 0.1}
+{"Package": "pkg", "Test": "TestBroken", "Action": "run"}
+{"Package": "pkg", "Test": "TestBroken", "Action": "fail", "Elapsed": 0.1}
+{"Package": "pkg", "Action": "fail"}
+`),
+		Execution: exec,
+	})
+	assert.NilError(t, err)
+	_, err = testjson.ScanTestOutput(testjson.ScanConfig{
+		RunID: 1,
+		Stdout: strings.NewReader(`{"Package": "pkg", "Test": "TestBroken", "Action": "run"}
+{"Package": "pkg", "Test": "TestBroken", "Action": "fail", "Elapsed": 0.1}
+{"Package": "pkg", "Test": "TestFlaky", "Action": "run"}
+{"Package": "pkg", "Test": "TestFlaky", "Action": "pass", "Elapsed": 0.1}
+{"Package": "pkg", "Action": "fail"}
+`),
+		Execution: exec,
+	})
+	assert.NilError(t, err)
+
+	type result struct {
+		name     testjson.TestName
+		attempts []testjson.Action
+		failures int
+		rerun    bool
+	}
+	var actual []result
+	for _, test := range Group(exec.Package("pkg")) {
+		r := result{name: test.Name, failures: test.Failures(), rerun: test.Rerun()}
+		for _, a := range test.Attempts {
+			r.attempts = append(r.attempts, a.Result)
+		}
+		actual = append(actual, r)
+	}
+
+	fail, pass := testjson.ActionFail, testjson.ActionPass
+	expected := []result{
+		{name: "TestFlaky", attempts: []testjson.Action{fail, pass}, failures: 1, rerun: true},
+		{name: "TestOnce", attempts: []testjson.Action{pass}},
+		{name: "TestBroken", attempts: []testjson.Action{fail, fail}, failures: 2, rerun: true},
+	}
+	assert.DeepEqual(t, actual, expected, cmp.AllowUnexported(result{}))
+
+	var names []testjson.TestName
+	for _, test := range Reruns(exec.Package("pkg")) {
+		names = append(names, test.Name)
+	}
+	assert.DeepEqual(t, names, []testjson.TestName{"TestFlaky", "TestBroken"})
+}
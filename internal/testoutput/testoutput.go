@@ -0,0 +1,12 @@
+// Package testoutput filters the output of test cases for reports.
+package testoutput
+
+import "strings"
+
+// IsFramingLine returns true if the line is one of the === RUN, === PAUSE, or
+// === CONT lines printed by 'go test -v'.
+func IsFramingLine(line string) bool {
+	return strings.HasPrefix(line, "=== RUN   Test") ||
+		strings.HasPrefix(line, "=== PAUSE Test") ||
+		strings.HasPrefix(line, "=== CONT  Test")
+}
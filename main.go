@@ -2,7 +2,6 @@ package main
 
 import (
 	"os"
-	"os/exec"
 
 	"gotest.tools/gotestsum/cmd"
 	"gotest.tools/gotestsum/cmd/tool"
@@ -11,12 +10,12 @@ import (
 
 func main() {
 	err := route(os.Args)
-	switch err.(type) {
-	case nil:
+	switch {
+	case err == nil:
 		return
-	case *exec.ExitError:
-		// go test should already report the error to stderr, exit with
-		// the same status code
+	case cmd.IsExitCoder(err):
+		// go test, or the summary of the failed tests, should already report
+		// the error, exit with the same status code
 		os.Exit(cmd.ExitCodeWithDefault(err))
 	default:
 		log.Error(err.Error())
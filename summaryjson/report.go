@@ -0,0 +1,171 @@
+/*Package summaryjson creates a machine readable JSON report from a
+testjson.Execution.
+
+The Report is the schema of the file written by --summary-json, and of the
+document written to the stdin of the --post-run-command. A post-run command can
+decode stdin into a Report.
+*/
+package summaryjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gotest.tools/gotestsum/internal/reruns"
+	"gotest.tools/gotestsum/testjson"
+)
+
+// Version of the Report document. The version is incremented when a field is
+// removed or changed in a way that is not backwards compatible.
+const Version = 1
+
+// Report is a summary of a test execution.
+type Report struct {
+	Version int `json:"version"`
+	// Started is the time the execution started.
+	Started time.Time `json:"started"`
+	// Elapsed time of the execution in seconds, including the time to build.
+	Elapsed float64 `json:"elapsed"`
+	// Total number of test cases, including re-runs.
+	Total int `json:"total"`
+	// Packages is a sorted list of every package in the execution.
+	Packages []Package `json:"packages"`
+	// Failed test cases, with their output. A failed test case with an empty
+	// Test name is a package which failed without any test failures, for
+	// example when TestMain exits non-zero.
+	Failed []TestCase `json:"failed"`
+	// Skipped test cases, with their output.
+	Skipped []TestCase `json:"skipped"`
+	// Quarantined test cases which failed, with their output.
+	Quarantined []TestCase `json:"quarantined,omitempty"`
+	// Errors are lines of output from stderr of the 'go test' process.
+	Errors []string `json:"errors"`
+	// Reruns is a list of every test case that was run more than once
+	// by --rerun-fails.
+	Reruns []Rerun `json:"reruns,omitempty"`
+}
+
+// Package is the result of a single package.
+type Package struct {
+	Name string `json:"name"`
+	// Result is pass, fail, or skip when the package has no tests.
+	Result testjson.Action `json:"result"`
+	// Elapsed time in seconds, the sum of elapsed time of all test cases.
+	Elapsed float64 `json:"elapsed"`
+	// Coverage output from go test, ex: coverage: 91.1% of statements.
+	Coverage string `json:"coverage,omitempty"`
+	Cached   bool   `json:"cached"`
+	Total    int    `json:"total"`
+	Passed   int    `json:"passed"`
+	Failed   int    `json:"failed"`
+	Skipped  int    `json:"skipped"`
+}
+
+// TestCase is a failed or skipped test case.
+type TestCase struct {
+	Package string `json:"package"`
+	Test    string `json:"test"`
+	// Elapsed time in seconds.
+	Elapsed float64 `json:"elapsed"`
+	// RunID is greater than 0 when the test case is from a re-run.
+	RunID  int      `json:"runID,omitempty"`
+	Output []string `json:"output"`
+}
+
+// Rerun is the number of attempts of a test case that was re-run.
+type Rerun struct {
+	Package  string `json:"package"`
+	Test     string `json:"test"`
+	Attempts int    `json:"attempts"`
+	Failures int    `json:"failures"`
+	// Result of the last attempt.
+	Result testjson.Action `json:"result"`
+}
+
+// Write creates a JSON Report and writes it to out.
+func Write(out io.Writer, exec *testjson.Execution) error {
+	return write(out, New(exec))
+}
+
+func write(out io.Writer, report Report) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write JSON summary: %v", err)
+	}
+	return nil
+}
+
+// New returns a Report of the execution.
+func New(exec *testjson.Execution) Report {
+	report := Report{
+		Version:     Version,
+		Started:     exec.Started(),
+		Elapsed:     exec.Elapsed().Seconds(),
+		Total:       exec.Total(),
+		Packages:    []Package{},
+		Failed:      newTestCases(exec, exec.Failed()),
+		Skipped:     newTestCases(exec, exec.Skipped()),
+		Quarantined: newTestCases(exec, exec.Quarantined()),
+		Errors:      exec.Errors(),
+	}
+	if report.Errors == nil {
+		report.Errors = []string{}
+	}
+	if len(report.Quarantined) == 0 {
+		report.Quarantined = nil
+	}
+
+	for _, name := range exec.Packages() {
+		pkg := exec.Package(name)
+		report.Packages = append(report.Packages, Package{
+			Name:     name,
+			Result:   pkg.Result(),
+			Elapsed:  pkg.Elapsed().Seconds(),
+			Coverage: pkg.Coverage(),
+			Cached:   pkg.Cached(),
+			Total:    pkg.Total,
+			Passed:   len(pkg.Passed),
+			Failed:   len(pkg.Failed),
+			Skipped:  len(pkg.Skipped),
+		})
+		report.Reruns = append(report.Reruns, newReruns(pkg)...)
+	}
+	return report
+}
+
+func newTestCases(exec *testjson.Execution, cases []testjson.TestCase) []TestCase {
+	result := make([]TestCase, 0, len(cases))
+	for _, tc := range cases {
+		output := exec.OutputLines(tc)
+		if output == nil {
+			output = []string{}
+		}
+		result = append(result, TestCase{
+			Package: tc.Package,
+			Test:    tc.Test.Name(),
+			Elapsed: tc.Elapsed.Seconds(),
+			RunID:   tc.RunID,
+			Output:  output,
+		})
+	}
+	return result
+}
+
+// newReruns returns a Rerun for every test case in the package that has at
+// least one TestCase with a RunID greater than 0.
+func newReruns(pkg *testjson.Package) []Rerun {
+	var result []Rerun // nolint: prealloc
+	for _, test := range reruns.Reruns(pkg) {
+		result = append(result, Rerun{
+			Package:  test.Package,
+			Test:     test.Name.Name(),
+			Attempts: len(test.Attempts),
+			Failures: test.Failures(),
+			Result:   test.Last().Result,
+		})
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package summaryjson
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/gotestsum/testjson"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+)
+
+func TestNew(t *testing.T) {
+	exec := createExecution(t)
+
+	report := New(exec)
+	assert.Equal(t, report.Version, Version)
+	assert.Assert(t, !report.Started.IsZero())
+	report.Started = time.Time{}
+	report.Elapsed = 0
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, write(out, report))
+	golden.Assert(t, out.String(), "summary-report.golden")
+}
+
+func createExecution(t *testing.T) *testjson.Execution {
+	exec := testjson.NewExecution()
+	_, err := testjson.ScanTestOutput(testjson.ScanConfig{
+		Stdout: strings.NewReader(`{"Package": "example.com/one", "Action": "output", "Output": "coverage: 50.0% of statements\n"}
+{"Package": "example.com/one", "Action": "output", "Output": "ok  \texample.com/one\t(cached)\tcoverage: 50.0% of statements\n"}
+{"Package": "example.com/one", "Test": "TestPass", "Action": "run"}
+{"Package": "example.com/one", "Test": "TestPass", "Action": "pass", "Elapsed": 0.1}
+{"Package": "example.com/one", "Test": "TestSkip", "Action": "run"}
+{"Package": "example.com/one", "Test": "TestSkip", "Action": "output", "Output": "    one_test.go:10: not today\n"}
+{"Package": "example.com/one", "Test": "TestSkip", "Action": "skip"}
+{"Package": "example.com/one", "Action": "pass", "Elapsed": 0.2}
+{"Package": "example.com/two", "Test": "TestFlaky", "Action": "run"}
+{"Package": "example.com/two", "Test": "TestFlaky", "Action": "output", "Output": "    two_test.go:20: unlucky\n"}
+{"Package": "example.com/two", "Test": "TestFlaky", "Action": "fail", "Elapsed": 0.3}
+{"Package": "example.com/two", "Action": "fail", "Elapsed": 0.4}
+`),
+		Stderr:    strings.NewReader("some stderr\n"),
+		Execution: exec,
+	})
+	assert.NilError(t, err)
+
+	_, err = testjson.ScanTestOutput(testjson.ScanConfig{
+		RunID: 1,
+		Stdout: strings.NewReader(`{"Package": "example.com/two", "Test": "TestFlaky", "Action": "run"}
+{"Package": "example.com/two", "Test": "TestFlaky", "Action": "pass", "Elapsed": 0.3}
+{"Package": "example.com/two", "Action": "pass", "Elapsed": 0.4}
+`),
+		Execution: exec,
+	})
+	assert.NilError(t, err)
+	return exec
+}
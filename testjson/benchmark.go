@@ -0,0 +1,114 @@
+package testjson
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Benchmark is the result of a single run of a benchmark, parsed from a line
+// of 'go test -bench' output.
+type Benchmark struct {
+	Package string
+	// Name of the benchmark, without the GOMAXPROCS suffix.
+	Name string
+	// Procs is the value of GOMAXPROCS used to run the benchmark, from the
+	// suffix of the name. Procs is 0 when the name has no suffix.
+	Procs int
+	// Iterations is the number of times the benchmark loop ran (b.N).
+	Iterations int
+	// Metrics reported by the benchmark, in the order they were printed. This
+	// includes ns/op, B/op, and allocs/op, and any custom metrics reported with
+	// testing.B.ReportMetric.
+	Metrics []BenchmarkMetric
+	// RunID from the ScanConfig which produced this benchmark.
+	RunID int
+}
+
+// BenchmarkMetric is a single value reported by a benchmark.
+type BenchmarkMetric struct {
+	Value float64
+	// Unit of the value, ex: ns/op.
+	Unit string
+}
+
+// FullName returns the name of the benchmark with the GOMAXPROCS suffix, as it
+// is printed by 'go test'.
+func (b Benchmark) FullName() string {
+	if b.Procs == 0 {
+		return b.Name
+	}
+	return b.Name + "-" + strconv.Itoa(b.Procs)
+}
+
+// Metric returns the value of the metric with unit. Returns false if the
+// benchmark did not report the metric.
+func (b Benchmark) Metric(unit string) (float64, bool) {
+	for _, m := range b.Metrics {
+		if m.Unit == unit {
+			return m.Value, true
+		}
+	}
+	return 0, false
+}
+
+// parseBenchmark parses a line of benchmark output in the format:
+//
+//	BenchmarkName-8   1000   1234 ns/op   56 B/op   2 allocs/op
+//
+// Returns false if the line is not a benchmark result.
+func parseBenchmark(line string) (Benchmark, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || len(fields)%2 != 0 || !strings.HasPrefix(fields[0], "Benchmark") {
+		return Benchmark{}, false
+	}
+	iterations, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Benchmark{}, false
+	}
+
+	b := Benchmark{Name: fields[0], Iterations: iterations}
+	if i := strings.LastIndex(b.Name, "-"); i > 0 {
+		if procs, err := strconv.Atoi(b.Name[i+1:]); err == nil {
+			b.Name, b.Procs = b.Name[:i], procs
+		}
+	}
+	for i := 2; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Benchmark{}, false
+		}
+		b.Metrics = append(b.Metrics, BenchmarkMetric{Value: value, Unit: fields[i+1]})
+	}
+	return b, true
+}
+
+// Benchmarks returns the result of every benchmark run in the package, in the
+// order they were received.
+func (p *Package) Benchmarks() []Benchmark {
+	return p.benchmarks
+}
+
+// Benchmarks returns the result of every benchmark run in the execution,
+// sorted by package.
+func (e *Execution) Benchmarks() []Benchmark {
+	var result []Benchmark
+	for _, name := range e.Packages() {
+		result = append(result, e.packages[name].benchmarks...)
+	}
+	return result
+}
+
+// addBenchmark adds the benchmark result from the output of event, if the
+// output is a benchmark result.
+func (p *Package) addBenchmark(event TestEvent) {
+	if event.Action != ActionOutput && event.Action != ActionBench {
+		return
+	}
+	b, ok := parseBenchmark(event.Output)
+	if !ok {
+		return
+	}
+	b.Package = event.Package
+	b.RunID = event.RunID
+	p.benchmarks = append(p.benchmarks, b)
+}
@@ -0,0 +1,87 @@
+package testjson
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestParseBenchmark(t *testing.T) {
+	testCases := []struct {
+		line     string
+		expected Benchmark
+		ok       bool
+	}{
+		{
+			line: "BenchmarkAlloc-8   \t 3145728\t       381.2 ns/op\t     128 B/op\t       2 allocs/op\n",
+			expected: Benchmark{
+				Name:       "BenchmarkAlloc",
+				Procs:      8,
+				Iterations: 3145728,
+				Metrics: []BenchmarkMetric{
+					{Value: 381.2, Unit: "ns/op"},
+					{Value: 128, Unit: "B/op"},
+					{Value: 2, Unit: "allocs/op"},
+				},
+			},
+			ok: true,
+		},
+		{
+			line: "BenchmarkParse/with-dash \t     100\t         1.200 ns/op\t         3.000 widgets/op\n",
+			expected: Benchmark{
+				Name:       "BenchmarkParse/with-dash",
+				Iterations: 100,
+				Metrics: []BenchmarkMetric{
+					{Value: 1.2, Unit: "ns/op"},
+					{Value: 3, Unit: "widgets/op"},
+				},
+			},
+			ok: true,
+		},
+		{line: "BenchmarkAlloc\n"},
+		{line: "BenchmarkAlloc-8 \t 100\t 3 ns/op\t 12\n"},
+		{line: "BenchmarkAlloc-8 \t many\t 3 ns/op\n"},
+		{line: "    bench_test.go:21: Benchmark 1 2 ns/op\n"},
+	}
+	for _, tc := range testCases {
+		actual, ok := parseBenchmark(tc.line)
+		assert.Equal(t, ok, tc.ok, tc.line)
+		assert.DeepEqual(t, actual, tc.expected)
+	}
+}
+
+func TestExecution_Benchmarks(t *testing.T) {
+	exec, err := ScanTestOutput(ScanConfig{
+		Stdout: strings.NewReader(`{"Package": "example.com/two", "Test": "BenchmarkB", "Action": "output", "Output": "BenchmarkB-4 \t 10\t 20 ns/op\n"}
+{"Package": "example.com/one", "Action": "output", "Output": "BenchmarkA-4 \t 10\t 30 ns/op\n"}
+{"Package": "example.com/one", "Action": "output", "Output": "not a BenchmarkA-4 \t 10\t 30 ns/op\n"}
+`),
+		RunID: 2,
+	})
+	assert.NilError(t, err)
+
+	expected := []Benchmark{
+		{
+			Package:    "example.com/one",
+			Name:       "BenchmarkA",
+			Procs:      4,
+			Iterations: 10,
+			Metrics:    []BenchmarkMetric{{Value: 30, Unit: "ns/op"}},
+			RunID:      2,
+		},
+		{
+			Package:    "example.com/two",
+			Name:       "BenchmarkB",
+			Procs:      4,
+			Iterations: 10,
+			Metrics:    []BenchmarkMetric{{Value: 20, Unit: "ns/op"}},
+			RunID:      2,
+		},
+	}
+	assert.DeepEqual(t, exec.Benchmarks(), expected)
+	assert.Equal(t, expected[0].FullName(), "BenchmarkA-4")
+	value, ok := expected[1].Metric("ns/op")
+	assert.Assert(t, ok)
+	assert.Equal(t, value, 20.0)
+}
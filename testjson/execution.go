@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -89,6 +90,16 @@ type Package struct {
 	action Action
 	// cached is true if the package was marked as (cached)
 	cached bool
+	// started is the time of the first event for the package.
+	started time.Time
+	// elapsed is the elapsed time from the pass or fail event of the package.
+	elapsed time.Duration
+	// keepPassedOutput is true when the output of passed tests should be
+	// kept. See Execution.SetKeepPassedOutput.
+	keepPassedOutput bool
+	// benchmarks are the results parsed from the benchmark output of the
+	// package.
+	benchmarks []Benchmark
 }
 
 // Result returns if the package passed, failed, or was skipped because there
@@ -97,6 +108,55 @@ func (p *Package) Result() Action {
 	return p.action
 }
 
+// Coverage returns the code coverage output for the package without the
+// trailing newline (ex: coverage: 91.1% of statements). Returns an empty string
+// if the package has no coverage output.
+func (p *Package) Coverage() string {
+	return p.coverage
+}
+
+// CoveragePercent returns the percentage of statements covered by the tests
+// in the package, parsed from the coverage output. Returns false if the
+// package has no coverage output.
+func (p *Package) CoveragePercent() (float64, bool) {
+	return parseCoveragePercent(p.coverage)
+}
+
+// parseCoveragePercent parses the percentage from coverage output
+// (ex: coverage: 91.1% of statements).
+func parseCoveragePercent(coverage string) (float64, bool) {
+	value := strings.TrimPrefix(coverage, "coverage: ")
+	end := strings.Index(value, "%")
+	if value == coverage || end < 0 {
+		return 0, false
+	}
+	percent, err := strconv.ParseFloat(value[:end], 64)
+	if err != nil {
+		return 0, false
+	}
+	return percent, true
+}
+
+// Started returns the time of the first event received for the package. Returns
+// the zero value if the events did not include a time.
+func (p *Package) Started() time.Time {
+	return p.started
+}
+
+// Cached returns true if the test results for the package were cached.
+func (p *Package) Cached() bool {
+	return p.cached
+}
+
+// ElapsedFromEvent returns the elapsed time of the package reported by
+// 'go test' in the pass or fail event of the package. Unlike Elapsed, it
+// includes the time spent outside of tests, and is not the sum of parallel
+// tests. When the package was re-run, the elapsed time of the first run is
+// returned. Returns 0 if there was no pass or fail event with an elapsed time.
+func (p *Package) ElapsedFromEvent() time.Duration {
+	return p.elapsed
+}
+
 // Elapsed returns the sum of the elapsed time for all tests in the package.
 func (p *Package) Elapsed() time.Duration {
 	elapsed := time.Duration(0)
@@ -218,9 +278,23 @@ const neverFinished time.Duration = -1
 //
 // This is done to work around 'go test' not sending the ActionFail TestEvents
 // in some cases, when a test panics.
+//
+// 'go test' never sends an ActionPass TestEvent for a benchmark, so benchmarks
+// in a package which passed are added to Passed instead.
 func (p *Package) end() []TestEvent {
 	result := make([]TestEvent, 0, len(p.running))
 	for k, tc := range p.running {
+		if p.action == ActionPass && isBenchmark(tc.Test) {
+			p.Passed = append(p.Passed, tc)
+			result = append(result, TestEvent{
+				Action:  ActionPass,
+				Package: tc.Package,
+				Test:    tc.Test.Name(),
+			})
+			delete(p.running, k)
+			continue
+		}
+
 		tc.Elapsed = neverFinished
 		p.Failed = append(p.Failed, tc)
 
@@ -268,14 +342,54 @@ type Execution struct {
 	errors     []string
 	done       bool
 	lastRunID  int
+
+	// lock serializes updates to the Execution, and calls to the EventHandler,
+	// when more than one ScanTestOutput shares the same Execution.
+	lock sync.Mutex
+	// scans is the number of calls to ScanTestOutput which are in progress.
+	scans int
+	// quarantine returns true if a test case is quarantined. May be nil.
+	quarantine func(TestCase) bool
+	// keepPassedOutput is copied to every Package.
+	keepPassedOutput bool
+	// errorsByPkg are the lines from errors, indexed by the package named in
+	// the build error header which preceded the lines.
+	errorsByPkg map[string][]string
+	// totalCoverage is the percentage of statements covered by all the
+	// packages. See Execution.SetTotalCoverage.
+	totalCoverage *float64
+	// recorded is true when the events are from an earlier run. The started
+	// and elapsed time are read from the time of the events. See
+	// Execution.SetRecorded.
+	recorded bool
+	// firstEvent and lastEvent are the times of the first and last event with
+	// a time.
+	firstEvent time.Time
+	lastEvent  time.Time
 }
 
 func (e *Execution) add(event TestEvent) {
 	pkg, ok := e.packages[event.Package]
 	if !ok {
 		pkg = newPackage()
+		pkg.keepPassedOutput = e.keepPassedOutput
 		e.packages[event.Package] = pkg
 	}
+	if pkg.started.IsZero() {
+		pkg.started = event.Time
+	}
+	if !event.Time.IsZero() {
+		if e.firstEvent.IsZero() || event.Time.Before(e.firstEvent) {
+			e.firstEvent = event.Time
+		}
+		if event.Time.After(e.lastEvent) {
+			e.lastEvent = event.Time
+		}
+	}
+	if event.RunID > e.lastRunID {
+		e.lastRunID = event.RunID
+	}
+	pkg.addBenchmark(event)
 	if event.PackageEvent() {
 		e.addPackageEvent(pkg, event)
 		return
@@ -287,8 +401,13 @@ func (e *Execution) addPackageEvent(pkg *Package, event TestEvent) {
 	switch event.Action {
 	case ActionPass, ActionFail:
 		pkg.action = event.Action
+		if event.RunID == 0 || pkg.elapsed == 0 {
+			pkg.elapsed = elapsedDuration(event.Elapsed)
+		}
 	case ActionOutput:
-		if isCoverageOutput(event.Output) {
+		// A re-run only runs the failed tests, so its coverage is much lower
+		// than the coverage of the first run.
+		if isCoverageOutput(event.Output) && (event.RunID == 0 || pkg.coverage == "") {
 			pkg.coverage = strings.TrimRight(event.Output, "\n")
 		}
 		if isCachedOutput(event.Output) {
@@ -361,6 +480,10 @@ func (p *Package) addTestEvent(event TestEvent) {
 	case ActionPass:
 		p.Passed = append(p.Passed, tc)
 
+		if p.keepPassedOutput {
+			return
+		}
+
 		// Do not immediately remove output for subtests, to work around a bug
 		// in 'go test' where output is attributed to the wrong sub test.
 		// github.com/golang/go/issues/29755.
@@ -385,6 +508,10 @@ func isCoverageOutput(output string) bool {
 		strings.HasSuffix(output, "% of statements\n"))
 }
 
+func isBenchmark(name TestName) bool {
+	return strings.HasPrefix(name.Name(), "Benchmark")
+}
+
 func isCachedOutput(output string) bool {
 	return strings.Contains(output, "\t(cached)")
 }
@@ -410,12 +537,50 @@ func (e *Execution) Packages() []string {
 
 var clock = clockwork.NewRealClock()
 
-// Elapsed returns the time elapsed since the execution started.
+// SetRecorded configures the Execution for events which were recorded by an
+// earlier run, for example when the events are read from a file. When recorded
+// is true, Started and Elapsed use the time of the events instead of the
+// current time.
+func (e *Execution) SetRecorded(recorded bool) {
+	e.recorded = recorded
+}
+
+// Started returns the time the execution started.
+func (e *Execution) Started() time.Time {
+	if e.recorded && !e.firstEvent.IsZero() {
+		return e.firstEvent
+	}
+	return e.started
+}
+
+// Elapsed returns the time elapsed since the execution started. When the
+// events were recorded, see SetRecorded, Elapsed returns the time between the
+// first and last event.
 func (e *Execution) Elapsed() time.Duration {
+	if e.recorded {
+		return e.lastEvent.Sub(e.firstEvent)
+	}
 	return clock.Now().Sub(e.started)
 }
 
-// Failed returns a list of all the failed test cases.
+// SetTotalCoverage sets the percentage of statements covered by all the
+// packages in the execution. The total can not be calculated from the coverage
+// of each package, so it must be set from a coverage profile.
+func (e *Execution) SetTotalCoverage(percent float64) {
+	e.totalCoverage = &percent
+}
+
+// TotalCoverage returns the value set by SetTotalCoverage. Returns false if the
+// total coverage was not set.
+func (e *Execution) TotalCoverage() (float64, bool) {
+	if e.totalCoverage == nil {
+		return 0, false
+	}
+	return *e.totalCoverage, true
+}
+
+// Failed returns a list of all the failed test cases. Failures of quarantined
+// test cases are not included, see Quarantined.
 func (e *Execution) Failed() []TestCase {
 	if e == nil {
 		return nil
@@ -428,11 +593,59 @@ func (e *Execution) Failed() []TestCase {
 		if pkg.TestMainFailed() {
 			failed = append(failed, TestCase{Package: name})
 		}
-		failed = append(failed, pkg.Failed...)
+		for _, tc := range pkg.Failed {
+			if !e.isQuarantined(pkg, tc) {
+				failed = append(failed, tc)
+			}
+		}
 	}
 	return failed
 }
 
+// SetQuarantine sets the function used to identify quarantined test cases.
+// Quarantined test cases still run, but their failures are returned by
+// Quarantined instead of Failed.
+func (e *Execution) SetQuarantine(fn func(TestCase) bool) {
+	e.quarantine = fn
+}
+
+// Quarantined returns a list of the failed test cases which are quarantined.
+func (e *Execution) Quarantined() []TestCase {
+	if e == nil || e.quarantine == nil {
+		return nil
+	}
+	var result []TestCase
+	for _, name := range sortedKeys(e.packages) {
+		pkg := e.packages[name]
+		for _, tc := range pkg.Failed {
+			if e.isQuarantined(pkg, tc) {
+				result = append(result, tc)
+			}
+		}
+	}
+	return result
+}
+
+// isQuarantined returns true if the failed test case is quarantined, or if
+// it is a root test case which only failed because of quarantined subtests.
+func (e *Execution) isQuarantined(pkg *Package, tc TestCase) bool {
+	switch {
+	case e.quarantine == nil:
+		return false
+	case e.quarantine(tc):
+		return true
+	case !tc.hasSubTestFailed:
+		return false
+	}
+	for _, sub := range pkg.Failed {
+		root, _ := sub.Test.Split()
+		if sub.Test.IsSubTest() && root == tc.Test.Name() && !e.quarantine(sub) {
+			return false
+		}
+	}
+	return true
+}
+
 // FilterFailedUnique filters a slice of failed TestCases by removing root test
 // case that have failed subtests.
 func FilterFailedUnique(tcs []TestCase) []TestCase {
@@ -472,16 +685,52 @@ func (e *Execution) Total() int {
 	return total
 }
 
-func (e *Execution) addError(err string) {
-	// Build errors start with a header
-	if strings.HasPrefix(err, "# ") {
-		return
-	}
+// addError adds a line from stderr. pkg is the package named by the most
+// recent build error header, or an empty string if there was no header.
+func (e *Execution) addError(pkg string, err string) {
 	e.errorsLock.Lock()
 	e.errors = append(e.errors, err)
+	if pkg != "" {
+		if e.errorsByPkg == nil {
+			e.errorsByPkg = make(map[string][]string)
+		}
+		e.errorsByPkg[pkg] = append(e.errorsByPkg[pkg], err)
+	}
 	e.errorsLock.Unlock()
 }
 
+// ErrorsByPackage returns the errors which followed a build error header,
+// indexed by the package named in the header.
+func (e *Execution) ErrorsByPackage() map[string][]string {
+	e.errorsLock.RLock()
+	defer e.errorsLock.RUnlock()
+	result := make(map[string][]string, len(e.errorsByPkg))
+	for pkg, errs := range e.errorsByPkg {
+		result[pkg] = errs
+	}
+	return result
+}
+
+// parseBuildErrorHeader returns the package name from a build error header. A
+// header is a line like '# pkg' or '# pkg [pkg.test]'.
+func parseBuildErrorHeader(line string) (string, bool) {
+	if !strings.HasPrefix(line, "# ") {
+		return "", false
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "# "))
+	if len(fields) == 0 {
+		return "", false
+	}
+	return fields[0], true
+}
+
+// SetKeepPassedOutput sets the Execution to keep the output of passed test
+// cases. By default the output of a test case is removed once it passes. Must
+// be called before the Execution is passed to ScanTestOutput.
+func (e *Execution) SetKeepPassedOutput(keep bool) {
+	e.keepPassedOutput = keep
+}
+
 // Errors returns a list of all the errors.
 func (e *Execution) Errors() []string {
 	e.errorsLock.RLock()
@@ -489,18 +738,27 @@ func (e *Execution) Errors() []string {
 	return e.errors
 }
 
-func (e *Execution) end() []TestEvent {
-	e.done = true
+// end the packages which were scanned by a single call to ScanTestOutput. The
+// Execution is marked done once every ScanTestOutput has ended.
+func (e *Execution) end(pkgs map[string]struct{}) []TestEvent {
+	e.scans--
+	e.done = e.scans == 0
 	var result []TestEvent // nolint: prealloc
-	for _, pkg := range e.packages {
-		result = append(result, pkg.end()...)
+	for _, name := range sortedKeys(e.packages) {
+		if _, ok := pkgs[name]; ok {
+			result = append(result, e.packages[name].end()...)
+		}
 	}
 	return result
 }
 
-// newExecution returns a new Execution and records the current time as the
+// NewExecution returns a new Execution and records the current time as the
 // time the test execution started.
-func newExecution() *Execution {
+//
+// An Execution is created by ScanTestOutput when ScanConfig.Execution is nil.
+// NewExecution is used to share a single Execution between multiple calls to
+// ScanTestOutput.
+func NewExecution() *Execution {
 	return &Execution{
 		started:  clock.Now(),
 		packages: make(map[string]*Package),
@@ -521,6 +779,9 @@ type ScanConfig struct {
 	Handler EventHandler
 	// Execution to populate while scanning. If nil a new one will be created
 	// and returned from ScanTestOutput.
+	//
+	// The same Execution may be used by multiple concurrent calls to
+	// ScanTestOutput. Each call should scan a different set of packages.
 	Execution *Execution
 }
 
@@ -538,6 +799,11 @@ type EventHandler interface {
 // Execution, calls the Handler for each event, and returns the Execution.
 //
 // If config.Handler is nil, a default no-op handler will be used.
+//
+// Events are added to the Execution, and events and lines from stderr are sent
+// to config.Handler, one at a time, even when ScanTestOutput is called
+// concurrently with a shared Execution. The Handler does not need to be safe for
+// concurrent use by multiple goroutines.
 func ScanTestOutput(config ScanConfig) (*Execution, error) {
 	if config.Stdout == nil {
 		return nil, fmt.Errorf("stdout reader must be non-nil")
@@ -550,31 +816,43 @@ func ScanTestOutput(config ScanConfig) (*Execution, error) {
 	}
 	execution := config.Execution
 	if execution == nil {
-		execution = newExecution()
+		execution = NewExecution()
 	}
+	execution.lock.Lock()
+	execution.scans++
 	execution.done = false
-	execution.lastRunID = config.RunID
+	if config.RunID > execution.lastRunID {
+		execution.lastRunID = config.RunID
+	}
+	execution.lock.Unlock()
 
+	pkgs := make(map[string]struct{})
 	var group errgroup.Group
 	group.Go(func() error {
-		return readStdout(config, execution)
+		return readStdout(config, execution, pkgs)
 	})
 	group.Go(func() error {
 		return readStderr(config, execution)
 	})
-	if err := group.Wait(); err != nil {
+	err := group.Wait()
+
+	execution.lock.Lock()
+	defer execution.lock.Unlock()
+	events := execution.end(pkgs)
+	if err != nil {
 		return execution, err
 	}
-	for _, event := range execution.end() {
+	for _, event := range events {
 		if err := config.Handler.Event(event, execution); err != nil {
 			return execution, err
 		}
 	}
-
 	return execution, nil
 }
 
-func readStdout(config ScanConfig, execution *Execution) error {
+// readStdout parses TestEvents from config.Stdout and adds them to execution.
+// The name of every package found in the events is added to pkgs.
+func readStdout(config ScanConfig, execution *Execution, pkgs map[string]struct{}) error {
 	scanner := bufio.NewScanner(config.Stdout)
 	for scanner.Scan() {
 		raw := scanner.Bytes()
@@ -582,32 +860,58 @@ func readStdout(config ScanConfig, execution *Execution) error {
 		switch {
 		case err == errBadEvent:
 			// nolint: errcheck
-			config.Handler.Err(errBadEvent.Error() + ": " + scanner.Text())
+			execution.handleErr(errBadEvent.Error()+": "+scanner.Text(), config.Handler)
 			continue
 		case err != nil:
 			return errors.Wrapf(err, "failed to parse test output: %s", string(raw))
 		}
 
-		event.RunID = config.RunID
-		execution.add(event)
-		if err := config.Handler.Event(event, execution); err != nil {
+		// Keep the RunID from the event when the config does not set one, so
+		// that the output from 'gotestsum tool merge' can be read again.
+		if config.RunID != 0 {
+			event.RunID = config.RunID
+		}
+		pkgs[event.Package] = struct{}{}
+		if err := execution.addAndHandle(event, config.Handler); err != nil {
 			return err
 		}
 	}
 	return errors.Wrap(scanner.Err(), "failed to scan test output")
 }
 
+// addAndHandle adds the event to the Execution and then sends it to handler.
+func (e *Execution) addAndHandle(event TestEvent, handler EventHandler) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.add(event)
+	return handler.Event(event, e)
+}
+
+// handleErr sends text to handler. The lock is held so that the handler is never
+// called by more than one goroutine at a time.
+func (e *Execution) handleErr(text string, handler EventHandler) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return handler.Err(text)
+}
+
 func readStderr(config ScanConfig, execution *Execution) error {
+	var pkg string
 	scanner := bufio.NewScanner(config.Stderr)
 	for scanner.Scan() {
 		line := scanner.Text()
-		if err := config.Handler.Err(line); err != nil {
+		if err := execution.handleErr(line, config.Handler); err != nil {
 			return fmt.Errorf("failed to handle stderr: %v", err)
 		}
 		if isGoModuleOutput(line) {
 			continue
 		}
-		execution.addError(line)
+		// Build errors start with a header
+		if name, ok := parseBuildErrorHeader(line); ok {
+			pkg = name
+			continue
+		}
+		execution.addError(pkg, line)
 	}
 	if err := scanner.Err(); err != nil {
 		return fmt.Errorf("failed to scan stderr: %v", err)
@@ -2,11 +2,13 @@ package testjson
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/sync/errgroup"
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/golden"
 )
@@ -28,7 +30,7 @@ func TestPackage_Elapsed(t *testing.T) {
 }
 
 func TestExecution_Add_PackageCoverage(t *testing.T) {
-	exec := newExecution()
+	exec := NewExecution()
 	exec.add(TestEvent{
 		Package: "mytestpkg",
 		Action:  ActionOutput,
@@ -44,6 +46,51 @@ func TestExecution_Add_PackageCoverage(t *testing.T) {
 		running: map[string]TestCase{},
 	}
 	assert.DeepEqual(t, pkg, expected, cmpPackage)
+
+	percent, ok := pkg.CoveragePercent()
+	assert.Assert(t, ok)
+	assert.Equal(t, percent, 33.1)
+}
+
+func TestExecution_Add_PackageCoverageWithRerun(t *testing.T) {
+	exec := NewExecution()
+	exec.add(TestEvent{
+		Package: "mytestpkg",
+		Action:  ActionOutput,
+		Output:  "coverage: 33.1% of statements\n",
+	})
+	exec.add(TestEvent{
+		Package: "mytestpkg",
+		Action:  ActionOutput,
+		Output:  "coverage: 2.4% of statements\n",
+		RunID:   1,
+	})
+
+	pkg := exec.Package("mytestpkg")
+	assert.Equal(t, pkg.Coverage(), "coverage: 33.1% of statements")
+	percent, ok := pkg.CoveragePercent()
+	assert.Assert(t, ok)
+	assert.Equal(t, percent, 33.1)
+}
+
+func TestPackage_ElapsedFromEvent(t *testing.T) {
+	exec := NewExecution()
+	exec.add(TestEvent{Package: "mytestpkg", Action: ActionFail, Elapsed: 2.5})
+	exec.add(TestEvent{Package: "mytestpkg", Action: ActionPass, Elapsed: 0.1, RunID: 1})
+
+	pkg := exec.Package("mytestpkg")
+	assert.Equal(t, pkg.ElapsedFromEvent(), 2500*time.Millisecond)
+}
+
+func TestParseCoveragePercent(t *testing.T) {
+	percent, ok := parseCoveragePercent("coverage: 91.1% of statements in ./...")
+	assert.Assert(t, ok)
+	assert.Equal(t, percent, 91.1)
+
+	_, ok = parseCoveragePercent("")
+	assert.Assert(t, !ok)
+	_, ok = parseCoveragePercent("coverage: [no statements]")
+	assert.Assert(t, !ok)
 }
 
 var cmpPackage = cmp.Options{
@@ -58,3 +105,89 @@ func TestScanTestOutput_MinimalConfig(t *testing.T) {
 	// a weak check to show that all the stdout was scanned
 	assert.Equal(t, exec.Total(), 46)
 }
+
+func TestScanTestOutput_ConcurrentWithSharedExecution(t *testing.T) {
+	inputs := []string{
+		`{"Package": "pkg/one", "Test": "TestOne", "Action": "run"}
+{"Package": "pkg/one", "Test": "TestOne", "Action": "pass"}
+{"Package": "pkg/one", "Test": "TestTwo", "Action": "run"}
+{"Package": "pkg/one", "Test": "TestTwo", "Action": "fail"}
+{"Package": "pkg/one", "Action": "fail"}
+`,
+		`{"Package": "pkg/two", "Test": "TestOne", "Action": "run"}
+{"Package": "pkg/two", "Test": "TestOne", "Action": "pass"}
+{"Package": "pkg/two", "Test": "TestTwo", "Action": "run"}
+{"Package": "pkg/two", "Action": "fail"}
+`,
+	}
+
+	exec := NewExecution()
+	handler := &countingHandler{}
+	var group errgroup.Group
+	for _, input := range inputs {
+		input := input
+		group.Go(func() error {
+			_, err := ScanTestOutput(ScanConfig{
+				Stdout:    strings.NewReader(input),
+				Handler:   handler,
+				Execution: exec,
+			})
+			return err
+		})
+	}
+	assert.NilError(t, group.Wait())
+
+	assert.Equal(t, exec.Total(), 4)
+	assert.Equal(t, len(exec.Failed()), 2)
+	assert.Assert(t, exec.done)
+	// 9 events from the input, and 1 artificial event for the missing fail
+	assert.Equal(t, handler.events, 10)
+}
+
+// countingHandler counts events without any locking, so that a data race is
+// detected by the race detector if events are sent concurrently.
+type countingHandler struct {
+	events int
+}
+
+func (h *countingHandler) Event(TestEvent, *Execution) error {
+	h.events++
+	return nil
+}
+
+func (h *countingHandler) Err(string) error {
+	return nil
+}
+
+func TestParseBuildErrorHeader(t *testing.T) {
+	var testCases = []struct {
+		line     string
+		expected string
+		ok       bool
+	}{
+		{line: "# example.com/pkg", expected: "example.com/pkg", ok: true},
+		{line: "# example.com/pkg [example.com/pkg.test]", expected: "example.com/pkg", ok: true},
+		{line: "#", ok: false},
+		{line: "pkg/file.go:12:3: undefined: foo", ok: false},
+	}
+	for _, tc := range testCases {
+		actual, ok := parseBuildErrorHeader(tc.line)
+		assert.Equal(t, actual, tc.expected, tc.line)
+		assert.Equal(t, ok, tc.ok, tc.line)
+	}
+}
+
+func TestExecution_SetKeepPassedOutput(t *testing.T) {
+	input := `{"Package": "pkg", "Test": "TestOne", "Action": "run"}
+{"Package": "pkg", "Test": "TestOne", "Action": "output", "Output": "a log line\n"}
+{"Package": "pkg", "Test": "TestOne", "Action": "pass"}
+{"Package": "pkg", "Action": "pass"}
+`
+	exec := NewExecution()
+	exec.SetKeepPassedOutput(true)
+	_, err := ScanTestOutput(ScanConfig{Stdout: strings.NewReader(input), Execution: exec})
+	assert.NilError(t, err)
+
+	pkg := exec.Package("pkg")
+	assert.Equal(t, pkg.Output(pkg.Passed[0].ID), "a log line\n")
+}
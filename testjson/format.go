@@ -205,6 +205,9 @@ type EventFormatter interface {
 }
 
 // NewEventFormatter returns a formatter for printing events.
+//
+// Some formatters print output after the last event. These formatters implement
+// io.Closer, and Close must be called after all events have been formatted.
 func NewEventFormatter(out io.Writer, format string) EventFormatter {
 	switch format {
 	case "debug":
@@ -223,6 +226,14 @@ func NewEventFormatter(out io.Writer, format string) EventFormatter {
 		return &formatAdapter{out, pkgNameFormat}
 	case "pkgname-and-test-fails", "short-with-failures":
 		return &formatAdapter{out, pkgNameWithFailuresFormat}
+	case "github-actions":
+		return &formatAdapter{out, githubActionsFormat}
+	case "teamcity":
+		return newTeamCityFormatter(out)
+	case "tap":
+		return newTAPFormatter(out)
+	case "status":
+		return NewStatusFormatter(out, 0)
 	default:
 		return nil
 	}
@@ -2,6 +2,8 @@ package testjson
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
 	"testing"
 	"time"
 
@@ -82,6 +84,11 @@ var expectedExecution = &Execution{
 	done:    true,
 	started: time.Now(),
 	errors:  []string{"internal/broken/broken.go:5:21: undefined: somepackage"},
+	errorsByPkg: map[string][]string{
+		"github.com/gotestyourself/gotestyourself/testjson/internal/broken": {
+			"internal/broken/broken.go:5:21: undefined: somepackage",
+		},
+	},
 	packages: map[string]*Package{
 		"github.com/gotestyourself/gotestyourself/testjson/internal/good": {
 			Total: 18,
@@ -118,7 +125,7 @@ var expectedExecution = &Execution{
 var cmpExecutionShallow = gocmp.Options{
 	gocmp.AllowUnexported(Execution{}, Package{}),
 	gocmp.FilterPath(stringPath("started"), opt.TimeWithThreshold(10*time.Second)),
-	cmpopts.IgnoreFields(Execution{}, "errorsLock"),
+	cmpopts.IgnoreFields(Execution{}, "errorsLock", "lock", "firstEvent", "lastEvent"),
 	cmpopts.EquateEmpty(),
 	cmpPackageShallow,
 }
@@ -127,6 +134,8 @@ var cmpPackageShallow = gocmp.Options{
 	gocmp.FilterPath(opt.PathField(Package{}, "output"), gocmp.Ignore()),
 	gocmp.FilterPath(opt.PathField(Package{}, "Passed"), gocmp.Ignore()),
 	gocmp.FilterPath(opt.PathField(Package{}, "subTests"), gocmp.Ignore()),
+	gocmp.FilterPath(opt.PathField(Package{}, "started"), gocmp.Ignore()),
+	gocmp.FilterPath(opt.PathField(Package{}, "elapsed"), gocmp.Ignore()),
 	gocmp.Comparer(func(x, y TestCase) bool {
 		return x.Test == y.Test
 	}),
@@ -214,6 +223,11 @@ var expectedCoverageExecution = &Execution{
 	done:    true,
 	started: time.Now(),
 	errors:  []string{"internal/broken/broken.go:5:21: undefined: somepackage"},
+	errorsByPkg: map[string][]string{
+		"gotest.tools/gotestsum/testjson/internal/broken": {
+			"internal/broken/broken.go:5:21: undefined: somepackage",
+		},
+	},
 	packages: map[string]*Package{
 		"gotest.tools/gotestsum/testjson/internal/good": {
 			Total: 18,
@@ -247,3 +261,116 @@ var expectedCoverageExecution = &Execution{
 		},
 	},
 }
+
+func TestScanTestOutput_WithGitHubActionsFormat(t *testing.T) {
+	defer patchPkgPathPrefix("github.com/gotestyourself/gotestyourself")()
+
+	shim := newFakeHandlerWithAdapter(githubActionsFormat, "go-test-json")
+	exec, err := ScanTestOutput(shim.Config(t))
+
+	assert.NilError(t, err)
+	golden.Assert(t, shim.out.String(), "github-actions-format.out")
+	golden.Assert(t, shim.err.String(), "github-actions-format.err")
+	assert.DeepEqual(t, exec, expectedExecution, cmpExecutionShallow)
+}
+
+func TestGitHubActionsAnnotation(t *testing.T) {
+	defer patchPkgPathPrefix("example.com/mod")()
+
+	tc := TestCase{Package: "example.com/mod/pkg", Test: "TestOne/sub,case"}
+	lines := []string{
+		"=== RUN   TestOne/sub,case\n",
+		"    one_test.go:12: expected 100%\n",
+		"        got: a:b\n",
+		"    --- FAIL: TestOne/sub,case (0.00s)\n",
+	}
+	expected := "::error file=pkg/one_test.go,line=12,title=pkg.TestOne/sub%2Ccase::" +
+		"one_test.go:12: expected 100%25%0Agot: a:b\n"
+	assert.Equal(t, githubActionsAnnotation("error", tc, lines), expected)
+}
+
+func TestScanTestOutput_WithTeamCityFormat(t *testing.T) {
+	out := new(bytes.Buffer)
+	shim := newFakeHandler(newTeamCityFormatter(out), "go-test-json")
+	exec, err := ScanTestOutput(shim.Config(t))
+
+	assert.NilError(t, err)
+	golden.Assert(t, out.String(), "teamcity-format.out")
+	golden.Assert(t, shim.err.String(), "teamcity-format.err")
+	assert.DeepEqual(t, exec, expectedExecution, cmpExecutionShallow)
+}
+
+func TestTeamCityFormatter_PauseAndCont(t *testing.T) {
+	out := new(bytes.Buffer)
+	formatter := newTeamCityFormatter(out)
+	exec := NewExecution()
+	for _, event := range []TestEvent{
+		{Package: "pkg", Test: "TestParallel", Action: ActionRun},
+		{Package: "pkg", Test: "TestParallel", Action: ActionPause},
+		{Package: "pkg", Test: "TestParallel", Action: ActionCont},
+	} {
+		exec.add(event)
+		assert.NilError(t, formatter.Format(event, exec))
+	}
+	expected := `##teamcity[testSuiteStarted name='pkg' flowId='pkg']
+##teamcity[flowStarted flowId='pkg.TestParallel' parent='pkg']
+##teamcity[testStarted name='TestParallel' captureStandardOutput='false' flowId='pkg.TestParallel']
+`
+	assert.Equal(t, out.String(), expected)
+}
+
+func TestTeamCityEscape(t *testing.T) {
+	actual := teamcityEscape("it's [a] |test|\r\nwith ✓")
+	assert.Equal(t, actual, "it|'s |[a|] ||test|||r|nwith |0x2713")
+}
+
+func TestScanTestOutput_WithTAPFormat(t *testing.T) {
+	out := new(bytes.Buffer)
+	formatter := newTAPFormatter(out)
+	shim := newFakeHandler(formatter, "go-test-json")
+	exec, err := ScanTestOutput(shim.Config(t))
+	assert.NilError(t, err)
+	assert.NilError(t, formatter.(io.Closer).Close())
+
+	golden.Assert(t, out.String(), "tap-format.out")
+	golden.Assert(t, shim.err.String(), "tap-format.err")
+	assert.DeepEqual(t, exec, expectedExecution, cmpExecutionShallow)
+}
+
+func TestWriteTAP(t *testing.T) {
+	shim := newFakeHandler(&formatAdapter{out: ioutil.Discard, format: debugFormat}, "go-test-json")
+	exec, err := ScanTestOutput(shim.Config(t))
+	assert.NilError(t, err)
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, WriteTAP(out, exec))
+	golden.Assert(t, out.String(), "tap-report.out")
+}
+
+func TestWriteTAP_EscapesDescriptions(t *testing.T) {
+	exec := NewExecution()
+	for _, event := range []TestEvent{
+		{Package: "pkg", Test: "TestX", Action: ActionRun},
+		{Package: "pkg", Test: "TestX/case_#1", Action: ActionRun},
+		{Package: "pkg", Test: "TestX/case_#1", Action: ActionPass},
+		{Package: "pkg", Test: "TestX/path\\to", Action: ActionRun},
+		{Package: "pkg", Test: "TestX/path\\to", Action: ActionPass},
+		{Package: "pkg", Test: "TestX", Action: ActionPass},
+		{Package: "pkg", Action: ActionPass},
+	} {
+		exec.add(event)
+	}
+
+	out := new(bytes.Buffer)
+	assert.NilError(t, WriteTAP(out, exec))
+	expected := `TAP version 13
+1..1
+# Subtest: pkg
+    1..3
+    ok 1 - TestX
+    ok 2 - TestX/case_\#1
+    ok 3 - TestX/path\\to
+ok 1 - pkg
+`
+	assert.Equal(t, out.String(), expected)
+}
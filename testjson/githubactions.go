@@ -0,0 +1,186 @@
+package testjson
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// githubActionsFormat prints a collapsible group of output for each package,
+// and an error annotation for each failed test. See
+// https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions
+func githubActionsFormat(event TestEvent, exec *Execution) (string, error) {
+	if !event.PackageEvent() {
+		return "", nil
+	}
+	switch event.Action {
+	case ActionPass, ActionFail, ActionSkip:
+	default:
+		return "", nil
+	}
+
+	pkg := exec.Package(event.Package)
+	buf := new(strings.Builder)
+	buf.WriteString("::group::" + githubActionsEscape(githubActionsGroupTitle(event, pkg)) + "\n")
+
+	var failed []TestCase
+	for _, tc := range sortedByID(pkg.TestCases()) {
+		if tc.RunID != event.RunID {
+			continue
+		}
+		fmt.Fprintf(buf, "%s %s (%s)\n",
+			testCaseResult(pkg, tc), tc.Test, FormatDurationAsSeconds(tc.Elapsed, 2))
+		if isFailed(pkg, tc) {
+			failed = append(failed, tc)
+		}
+	}
+	for _, tc := range failed {
+		buf.WriteString("\n=== FAIL " + tc.Test.Name() + "\n")
+		writeGitHubActionsOutput(buf, pkg.OutputLines(tc))
+	}
+	if pkg.TestMainFailed() {
+		writeGitHubActionsOutput(buf, pkg.OutputLines(TestCase{}))
+	}
+	buf.WriteString("::endgroup::\n")
+
+	for _, tc := range failed {
+		if tc.hasSubTestFailed {
+			// the annotation for the subtest includes the relevant output
+			continue
+		}
+		command := "error"
+		if exec.isQuarantined(pkg, tc) {
+			command = "warning"
+		}
+		buf.WriteString(githubActionsAnnotation(command, tc, pkg.OutputLines(tc)))
+	}
+	if pkg.TestMainFailed() {
+		tc := TestCase{Package: event.Package}
+		buf.WriteString(githubActionsAnnotation("error", tc, pkg.OutputLines(tc)))
+	}
+	return buf.String(), nil
+}
+
+func githubActionsGroupTitle(event TestEvent, pkg *Package) string {
+	result := strings.ToUpper(string(event.Action))
+	if event.Action == ActionSkip {
+		result = "EMPTY"
+	}
+	title := result + " " + RelativePackagePath(event.Package)
+	switch {
+	case pkg.cached:
+		title += cachedMessage
+	case elapsedDuration(event.Elapsed) != 0:
+		title += fmt.Sprintf(" (%s)", elapsedDuration(event.Elapsed))
+	}
+	if pkg.coverage != "" {
+		title += " (" + pkg.coverage + ")"
+	}
+	return title
+}
+
+func sortedByID(tcs []TestCase) []TestCase {
+	sort.Slice(tcs, func(i, j int) bool {
+		return tcs[i].ID < tcs[j].ID
+	})
+	return tcs
+}
+
+func isFailed(pkg *Package, tc TestCase) bool {
+	for _, failed := range pkg.Failed {
+		if failed.ID == tc.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func testCaseResult(pkg *Package, tc TestCase) string {
+	if isFailed(pkg, tc) {
+		return "FAIL"
+	}
+	for _, skipped := range pkg.Skipped {
+		if skipped.ID == tc.ID {
+			return "SKIP"
+		}
+	}
+	return "PASS"
+}
+
+func writeGitHubActionsOutput(buf *strings.Builder, lines []string) {
+	for _, line := range lines {
+		if isFramingLine(line) {
+			continue
+		}
+		// prevent the test output from being interpreted as a workflow command
+		if strings.HasPrefix(strings.TrimSpace(line), "::") {
+			line = " " + line
+		}
+		buf.WriteString(line)
+	}
+}
+
+// goTestLocation matches the file and line prefix added by t.Log and t.Error.
+var goTestLocation = regexp.MustCompile(`^\s+([^\s:]+\.go):(\d+): `)
+
+// githubActionsAnnotation returns a workflow command which creates an
+// annotation for the failed test case. The location of the annotation is the
+// first file:line found in the output of the test.
+func githubActionsAnnotation(command string, tc TestCase, lines []string) string {
+	pkgPath := RelativePackagePath(tc.Package)
+	title := joinPkgToTestName(pkgPath, tc.Test.Name())
+	if tc.Test == "" {
+		title = pkgPath + " failed"
+	}
+
+	var file, line string
+	var msg strings.Builder
+	for _, out := range lines {
+		if isFramingLine(out) || strings.HasPrefix(strings.TrimSpace(out), "--- FAIL: ") {
+			continue
+		}
+		if match := goTestLocation.FindStringSubmatch(out); match != nil && file == "" {
+			file, line = path.Join(pkgPath, match[1]), match[2]
+		}
+		msg.WriteString(strings.TrimSpace(out) + "\n")
+	}
+
+	props := []string{"title=" + githubActionsEscapeProperty(title)}
+	if file != "" {
+		props = append([]string{
+			"file=" + githubActionsEscapeProperty(file),
+			"line=" + line,
+		}, props...)
+	}
+	return fmt.Sprintf("::%s %s::%s\n",
+		command,
+		strings.Join(props, ","),
+		githubActionsEscape(strings.TrimSpace(msg.String())))
+}
+
+var githubActionsEscaper = strings.NewReplacer(
+	"%", "%25",
+	"\r", "%0D",
+	"\n", "%0A",
+)
+
+// githubActionsEscape the data of a workflow command.
+func githubActionsEscape(s string) string {
+	return githubActionsEscaper.Replace(s)
+}
+
+var githubActionsPropertyEscaper = strings.NewReplacer(
+	"%", "%25",
+	"\r", "%0D",
+	"\n", "%0A",
+	":", "%3A",
+	",", "%2C",
+)
+
+// githubActionsEscapeProperty escapes the value of a property of a workflow
+// command.
+func githubActionsEscapeProperty(s string) string {
+	return githubActionsPropertyEscaper.Replace(s)
+}
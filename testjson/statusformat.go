@@ -0,0 +1,291 @@
+package testjson
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh/terminal"
+	"gotest.tools/gotestsum/internal/dotwriter"
+	"gotest.tools/gotestsum/log"
+)
+
+// maxStatusTests is the number of running tests printed in the status footer.
+// Any other running tests are counted on a single line.
+const maxStatusTests = 10
+
+// statusInterval is how often the status footer is redrawn when there are no
+// events.
+const statusInterval = time.Second
+
+// statusFormatter prints a line for each test and package, like the testname
+// format, followed by a footer of the tests which are running. The footer is
+// redrawn after every event, and every statusInterval.
+type statusFormatter struct {
+	writer    *dotwriter.Writer
+	termWidth int
+	// expected is the number of tests from a previous run, used to print the
+	// progress. The progress is not printed when expected is 0.
+	expected int
+	interval time.Duration
+	now      func() time.Time
+
+	lock  sync.Mutex
+	tests map[statusKey]*statusTest
+	// done is the number of tests which have finished, not including the tests
+	// from a re-run.
+	done    int
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+type statusKey struct {
+	pkg  string
+	name string
+}
+
+type statusTest struct {
+	pkg  string
+	name string
+	// started is the time of the run event, or of the last cont event for a
+	// parallel test.
+	started time.Time
+	// paused is true when a parallel test is waiting for other tests to finish.
+	paused bool
+	// hasRunningSubTest is true when the test is waiting for a subtest.
+	hasRunningSubTest bool
+}
+
+// NewStatusFormatter returns a formatter which prints a line for each test and
+// package, and a footer of the tests which are running. expected is the number
+// of tests in a previous run, used to print the progress of the run. Use 0 when
+// the number is not known.
+//
+// The footer is only printed when stdout is a terminal. Otherwise the formatter
+// is the same as the testname format.
+func NewStatusFormatter(out io.Writer, expected int) EventFormatter {
+	w, _, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil || w == 0 {
+		log.Warnf("Failed to detect terminal width for status format, error: %v", err)
+		return &formatAdapter{format: testNameFormat, out: out}
+	}
+	return newStatusFormatter(out, w, expected)
+}
+
+func newStatusFormatter(out io.Writer, termWidth int, expected int) *statusFormatter {
+	return &statusFormatter{
+		writer:    dotwriter.New(out),
+		termWidth: termWidth,
+		expected:  expected,
+		interval:  statusInterval,
+		now:       time.Now,
+		tests:     make(map[statusKey]*statusTest),
+	}
+}
+
+func (f *statusFormatter) Format(event TestEvent, exec *Execution) error {
+	line, err := testNameFormat(event, exec)
+	if err != nil {
+		return err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.stop == nil && f.interval > 0 {
+		f.stop, f.stopped = make(chan struct{}), make(chan struct{})
+		go f.redraw(f.stop, f.stopped)
+	}
+
+	switch event.Action {
+	case ActionOutput, ActionBench:
+		if line == "" {
+			return nil
+		}
+	}
+	_, _ = f.writer.WritePermanent([]byte(line))
+	f.update(event)
+	f.writeFooter(f.writer)
+	return f.writer.Flush()
+}
+
+// update the running tests, and the number of tests which are done, from the
+// event. Must be called with the lock held.
+func (f *statusFormatter) update(event TestEvent) {
+	now := f.now()
+	if event.PackageEvent() {
+		if event.Action == ActionPass || event.Action == ActionFail {
+			// Remove any tests which did not end before the package, for
+			// example when the test binary panicked.
+			for key := range f.tests {
+				if key.pkg == event.Package {
+					delete(f.tests, key)
+				}
+			}
+		}
+		return
+	}
+
+	key := statusKey{pkg: event.Package, name: event.Test}
+	test := f.tests[key]
+	switch event.Action {
+	case ActionRun:
+		f.tests[key] = &statusTest{pkg: event.Package, name: event.Test, started: now}
+	case ActionPause:
+		if test != nil {
+			test.paused = true
+		}
+	case ActionCont:
+		if test != nil {
+			test.paused = false
+			test.started = now
+		}
+	case ActionPass, ActionFail, ActionSkip:
+		delete(f.tests, key)
+		// Tests from a re-run are not counted, so that the count can be
+		// compared to the number of tests in a previous run.
+		if event.RunID == 0 {
+			f.done++
+		}
+	default:
+		return
+	}
+
+	for _, test := range f.tests {
+		test.hasRunningSubTest = false
+	}
+	for _, test := range f.tests {
+		if TestName(test.name).IsSubTest() && !test.paused {
+			f.markParents(test)
+		}
+	}
+}
+
+// markParents marks every test which is a parent of sub as having a running
+// subtest.
+func (f *statusFormatter) markParents(sub *statusTest) {
+	for _, test := range f.tests {
+		if test.pkg == sub.pkg && strings.HasPrefix(sub.name, test.name+"/") {
+			test.hasRunningSubTest = true
+		}
+	}
+}
+
+// writeFooter writes the running tests, and the progress of the run, to out.
+// Must be called with the lock held.
+func (f *statusFormatter) writeFooter(out io.Writer) {
+	now := f.now()
+	var running, paused []*statusTest
+	for _, test := range f.tests {
+		switch {
+		case test.paused:
+			paused = append(paused, test)
+		case !test.hasRunningSubTest:
+			running = append(running, test)
+		}
+	}
+	sortStatusTests(running)
+	sortStatusTests(paused)
+
+	fmt.Fprintln(out)
+	for i, test := range append(running, paused...) {
+		if i == maxStatusTests {
+			f.writeLine(out, fmt.Sprintf("         ... and %d more", len(running)+len(paused)-i))
+			break
+		}
+		elapsed := "paused"
+		if !test.paused {
+			elapsed = FormatDurationAsSeconds(now.Sub(test.started), 1)
+		}
+		f.writeLine(out, fmt.Sprintf("  %7s  %s",
+			elapsed, joinPkgToTestName(RelativePackagePath(test.pkg), test.name)))
+	}
+	f.writeLine(out, f.progress(len(running), len(paused)))
+}
+
+// writeLine writes line to out, truncated to the width of the terminal
+// so that the line does not wrap.
+func (f *statusFormatter) writeLine(out io.Writer, line string) {
+	if runes := []rune(line); f.termWidth > 0 && len(runes) >= f.termWidth {
+		line = string(runes[:f.termWidth-1])
+	}
+	fmt.Fprintln(out, line)
+}
+
+func (f *statusFormatter) progress(running, paused int) string {
+	var done string
+	switch {
+	case f.expected > 0:
+		percent := f.done * 100 / f.expected
+		if percent > 100 {
+			percent = 100
+		}
+		done = fmt.Sprintf("%d/%d tests done (%d%%)", f.done, f.expected, percent)
+	default:
+		done = fmt.Sprintf("%d tests done", f.done)
+	}
+	return fmt.Sprintf("%s, %d running, %d paused", done, running, paused)
+}
+
+// sortStatusTests so that the tests which have been running the longest are
+// first.
+func sortStatusTests(tests []*statusTest) {
+	sort.Slice(tests, func(i, j int) bool {
+		a, b := tests[i], tests[j]
+		if !a.started.Equal(b.started) {
+			return a.started.Before(b.started)
+		}
+		if a.pkg != b.pkg {
+			return a.pkg < b.pkg
+		}
+		return a.name < b.name
+	})
+}
+
+// redraw the footer every interval, so that the elapsed time of the running
+// tests is updated when there are no events.
+func (f *statusFormatter) redraw(stop <-chan struct{}, stopped chan<- struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			f.lock.Lock()
+			f.writeFooter(f.writer)
+			if err := f.writer.Flush(); err != nil {
+				log.Warnf("Failed to write status: %v", err)
+			}
+			f.lock.Unlock()
+		}
+	}
+}
+
+// Close stops redrawing the footer, and clears it from the terminal.
+func (f *statusFormatter) Close() error {
+	return f.ClearFooter()
+}
+
+// ClearFooter stops redrawing the footer, and clears it from the terminal, so
+// that other output can be written. The footer is printed again after the next
+// event.
+func (f *statusFormatter) ClearFooter() error {
+	f.lock.Lock()
+	stop, stopped := f.stop, f.stopped
+	f.stop = nil
+	f.lock.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-stopped
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.writer.Clear()
+}
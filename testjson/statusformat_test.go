@@ -0,0 +1,123 @@
+package testjson
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+	"gotest.tools/v3/skip"
+)
+
+func TestScanTestOutput_WithStatusFormatter(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "golden file uses posix escape codes")
+	defer patchPkgPathPrefix("gotest.tools/gotestsum")()
+
+	out := new(bytes.Buffer)
+	formatter := newStatusFormatter(out, 100, 12)
+	formatter.interval = 0
+	formatter.now = fakeNow(time.Date(2020, 6, 20, 20, 0, 0, 0, time.UTC), 100*time.Millisecond)
+
+	shim := newFakeHandler(formatter, "")
+	_, err := ScanTestOutput(ScanConfig{
+		Stdout:  bytes.NewReader(golden.Get(t, "go-test-json-with-parallel-fails.out")),
+		Handler: shim,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, formatter.Close())
+	golden.Assert(t, out.String(), "status-format.out")
+}
+
+// fakeNow returns a function which returns start, and then a time which is
+// step later on every call.
+func fakeNow(start time.Time, step time.Duration) func() time.Time {
+	now := start.Add(-step)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestStatusFormatter_WriteFooter(t *testing.T) {
+	start := time.Date(2020, 6, 20, 20, 0, 0, 0, time.UTC)
+	formatter := newStatusFormatter(new(bytes.Buffer), 40, 0)
+	formatter.now = func() time.Time { return start.Add(time.Minute) }
+	formatter.done = 7
+	for i := 0; i < maxStatusTests+2; i++ {
+		key := statusKey{pkg: "example.com/pkg", name: fmt.Sprintf("TestWithAVeryLongName%02d", i)}
+		formatter.tests[key] = &statusTest{
+			pkg:     "example.com/pkg",
+			name:    fmt.Sprintf("TestWithAVeryLongName%02d", i),
+			started: start.Add(time.Duration(i) * time.Second),
+			paused:  i == 0,
+		}
+	}
+	formatter.tests[statusKey{pkg: "example.com/pkg", name: "TestParent"}] = &statusTest{
+		pkg:               "example.com/pkg",
+		name:              "TestParent",
+		hasRunningSubTest: true,
+	}
+
+	buf := new(bytes.Buffer)
+	formatter.writeFooter(buf)
+	expected := `
+    59.0s  example.com/pkg.TestWithAVer
+    58.0s  example.com/pkg.TestWithAVer
+    57.0s  example.com/pkg.TestWithAVer
+    56.0s  example.com/pkg.TestWithAVer
+    55.0s  example.com/pkg.TestWithAVer
+    54.0s  example.com/pkg.TestWithAVer
+    53.0s  example.com/pkg.TestWithAVer
+    52.0s  example.com/pkg.TestWithAVer
+    51.0s  example.com/pkg.TestWithAVer
+    50.0s  example.com/pkg.TestWithAVer
+         ... and 2 more
+7 tests done, 11 running, 1 paused
+`
+	assert.Equal(t, buf.String(), expected)
+}
+
+func TestStatusFormatter_Update(t *testing.T) {
+	formatter := newStatusFormatter(new(bytes.Buffer), 80, 0)
+	events := []TestEvent{
+		{Package: "pkg", Test: "TestOne", Action: ActionRun},
+		{Package: "pkg", Test: "TestTwo", Action: ActionRun},
+		{Package: "pkg", Test: "TestTwo/sub", Action: ActionRun},
+		{Package: "pkg", Test: "TestTwo/sub", Action: ActionPass},
+		{Package: "pkg", Test: "TestOne", Action: ActionFail},
+		{Package: "pkg", Test: "TestOne", Action: ActionRun, RunID: 1},
+		{Package: "pkg", Test: "TestOne", Action: ActionPass, RunID: 1},
+	}
+	for _, event := range events {
+		formatter.update(event)
+	}
+	assert.Equal(t, formatter.done, 2)
+	assert.Equal(t, len(formatter.tests), 1)
+	assert.Assert(t, formatter.tests[statusKey{pkg: "pkg", name: "TestTwo"}] != nil)
+
+	formatter.update(TestEvent{Package: "pkg", Action: ActionFail})
+	assert.Equal(t, len(formatter.tests), 0)
+}
+
+func TestStatusFormatter_ClearFooter(t *testing.T) {
+	formatter := newStatusFormatter(new(bytes.Buffer), 80, 0)
+	formatter.interval = time.Hour
+	exec := NewExecution()
+	format := func(event TestEvent) {
+		exec.add(event)
+		assert.NilError(t, formatter.Format(event, exec))
+	}
+
+	format(TestEvent{Package: "pkg", Test: "TestOne", Action: ActionRun})
+	assert.Assert(t, formatter.stop != nil, "expected the footer to be redrawn")
+
+	assert.NilError(t, formatter.ClearFooter())
+	assert.Assert(t, formatter.stop == nil, "expected the footer to stop being redrawn")
+
+	format(TestEvent{Package: "pkg", Test: "TestOne", Action: ActionPass})
+	assert.Assert(t, formatter.stop != nil, "expected the footer to be redrawn after an event")
+	assert.NilError(t, formatter.Close())
+}
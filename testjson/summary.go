@@ -3,12 +3,15 @@ package testjson
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 	"unicode"
 	"unicode/utf8"
 
 	"github.com/fatih/color"
+	"gotest.tools/gotestsum/internal/testoutput"
 )
 
 // Summary enumerates the sections which can be printed by PrintSummary
@@ -21,23 +24,30 @@ const (
 	SummarizeFailed
 	SummarizeErrors
 	SummarizeOutput
-	SummarizeAll = SummarizeSkipped | SummarizeFailed | SummarizeErrors | SummarizeOutput
+	SummarizeCoverage
+	SummarizeBenchmarks
+	SummarizeAll = SummarizeSkipped | SummarizeFailed | SummarizeErrors | SummarizeOutput |
+		SummarizeCoverage | SummarizeBenchmarks
 )
 
 var summaryValues = map[Summary]string{
-	SummarizeSkipped: "skipped",
-	SummarizeFailed:  "failed",
-	SummarizeErrors:  "errors",
-	SummarizeOutput:  "output",
+	SummarizeSkipped:    "skipped",
+	SummarizeFailed:     "failed",
+	SummarizeErrors:     "errors",
+	SummarizeOutput:     "output",
+	SummarizeCoverage:   "coverage",
+	SummarizeBenchmarks: "benchmarks",
 }
 
 var summaryFromValue = map[string]Summary{
-	"none":    SummarizeNone,
-	"skipped": SummarizeSkipped,
-	"failed":  SummarizeFailed,
-	"errors":  SummarizeErrors,
-	"output":  SummarizeOutput,
-	"all":     SummarizeAll,
+	"none":       SummarizeNone,
+	"skipped":    SummarizeSkipped,
+	"failed":     SummarizeFailed,
+	"errors":     SummarizeErrors,
+	"output":     SummarizeOutput,
+	"coverage":   SummarizeCoverage,
+	"benchmarks": SummarizeBenchmarks,
+	"all":        SummarizeAll,
 }
 
 func (s Summary) String() string {
@@ -69,11 +79,18 @@ func NewSummary(value string) (Summary, bool) {
 // followed by a DONE line to out.
 func PrintSummary(out io.Writer, execution *Execution, opts Summary) {
 	execSummary := newExecSummary(execution, opts)
+	if opts.Includes(SummarizeBenchmarks) {
+		writeBenchmarkSummary(out, execution)
+	}
+	if opts.Includes(SummarizeCoverage) {
+		writeCoverageSummary(out, execution)
+	}
 	if opts.Includes(SummarizeSkipped) {
 		writeTestCaseSummary(out, execSummary, formatSkipped())
 	}
 	if opts.Includes(SummarizeFailed) {
 		writeTestCaseSummary(out, execSummary, formatFailed())
+		writeTestCaseSummary(out, execSummary, formatQuarantined())
 	}
 
 	errors := execution.Errors()
@@ -81,11 +98,12 @@ func PrintSummary(out io.Writer, execution *Execution, opts Summary) {
 		writeErrorSummary(out, errors)
 	}
 
-	fmt.Fprintf(out, "\n%s %d tests%s%s%s in %s\n",
+	fmt.Fprintf(out, "\n%s %d tests%s%s%s%s in %s\n",
 		formatExecStatus(execution),
 		execution.Total(),
 		formatTestCount(len(execution.Skipped()), "skipped", ""),
 		formatTestCount(len(execution.Failed()), "failure", "s"),
+		formatTestCount(len(execution.Quarantined()), "quarantined", ""),
 		formatTestCount(countErrors(errors), "error", "s"),
 		FormatDurationAsSeconds(execution.Elapsed(), 3))
 }
@@ -129,6 +147,51 @@ func writeErrorSummary(out io.Writer, errors []string) {
 	}
 }
 
+// writeCoverageSummary prints the coverage of every package which has coverage
+// output, followed by the total coverage if it was set.
+func writeCoverageSummary(out io.Writer, execution *Execution) {
+	var lines []string
+	for _, name := range execution.Packages() {
+		if percent, ok := execution.Package(name).CoveragePercent(); ok {
+			lines = append(lines, formatCoverageLine(percent, RelativePackagePath(name)))
+		}
+	}
+	total, hasTotal := execution.TotalCoverage()
+	if len(lines) == 0 && !hasTotal {
+		return
+	}
+	fmt.Fprintln(out, color.GreenString("\n=== Coverage"))
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
+	if hasTotal {
+		fmt.Fprintln(out, formatCoverageLine(total, "total"))
+	}
+}
+
+func formatCoverageLine(percent float64, name string) string {
+	return fmt.Sprintf("%6.1f%%  %s", percent, name)
+}
+
+// writeBenchmarkSummary prints the result of every benchmark, in the format
+// used by 'go test -bench', followed by the name of the package.
+func writeBenchmarkSummary(out io.Writer, execution *Execution) {
+	benchmarks := execution.Benchmarks()
+	if len(benchmarks) == 0 {
+		return
+	}
+	fmt.Fprintln(out, color.CyanString("\n=== Benchmarks"))
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, b := range benchmarks {
+		fmt.Fprintf(w, "%s\t%d", b.FullName(), b.Iterations)
+		for _, m := range b.Metrics {
+			fmt.Fprintf(w, "\t%s %s", strconv.FormatFloat(m.Value, 'f', -1, 64), m.Unit)
+		}
+		fmt.Fprintf(w, "\t%s\n", RelativePackagePath(b.Package))
+	}
+	w.Flush() // nolint: errcheck
+}
+
 // countErrors in stderr lines. Build errors may include multiple lines where
 // subsequent lines are indented.
 // FIXME: Panics will include multiple lines, and are still overcounted.
@@ -146,6 +209,7 @@ func countErrors(errors []string) int {
 type executionSummary interface {
 	Failed() []TestCase
 	Skipped() []TestCase
+	Quarantined() []TestCase
 	OutputLines(TestCase) []string
 }
 
@@ -210,6 +274,20 @@ func formatFailed() testCaseFormatConfig {
 	}
 }
 
+func formatQuarantined() testCaseFormatConfig {
+	withColor := color.CyanString
+	return testCaseFormatConfig{
+		header: withColor("Quarantined"),
+		prefix: withColor("QUARANTINED"),
+		filter: func(testName string, line string) bool {
+			return strings.HasPrefix(line, "--- FAIL: "+testName+" ")
+		},
+		getter: func(execution executionSummary) []TestCase {
+			return execution.Quarantined()
+		},
+	}
+}
+
 func formatSkipped() testCaseFormatConfig {
 	withColor := color.YellowString
 	return testCaseFormatConfig{
@@ -225,7 +303,5 @@ func formatSkipped() testCaseFormatConfig {
 }
 
 func isFramingLine(line string) bool {
-	return strings.HasPrefix(line, "=== RUN   Test") ||
-		strings.HasPrefix(line, "=== PAUSE Test") ||
-		strings.HasPrefix(line, "=== CONT  Test")
+	return testoutput.IsFramingLine(line)
 }
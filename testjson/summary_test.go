@@ -26,7 +26,7 @@ func TestSummary_String(t *testing.T) {
 		{
 			name:     "all",
 			summary:  SummarizeAll,
-			expected: "skipped,failed,errors,output",
+			expected: "skipped,failed,errors,output,coverage,benchmarks",
 		},
 		{
 			name:     "one value",
@@ -328,3 +328,88 @@ func TestPrintSummary_WithRerunID(t *testing.T) {
 	PrintSummary(buf, exec, SummarizeAll)
 	golden.Assert(t, buf.String(), "summary-with-run-id.out")
 }
+
+func TestPrintSummary_WithQuarantinedTests(t *testing.T) {
+	_, reset := patchClock()
+	defer reset()
+
+	exec, err := ScanTestOutput(ScanConfig{
+		Stdout: strings.NewReader(`{"Package": "pkg", "Test": "TestOne", "Action": "run"}
+{"Package": "pkg", "Test": "TestOne", "Action": "output", "Output": "=== RUN   TestOne\n"}
+{"Package": "pkg", "Test": "TestOne", "Action": "output", "Output": "    one_test.go:10: flaky failure\n"}
+{"Package": "pkg", "Test": "TestOne", "Action": "output", "Output": "--- FAIL: TestOne (0.00s)\n"}
+{"Package": "pkg", "Test": "TestOne", "Action": "fail"}
+{"Package": "pkg", "Test": "TestTwo", "Action": "run"}
+{"Package": "pkg", "Test": "TestTwo/sub", "Action": "run"}
+{"Package": "pkg", "Test": "TestTwo/sub", "Action": "fail"}
+{"Package": "pkg", "Test": "TestTwo", "Action": "fail"}
+{"Package": "pkg", "Test": "TestThree", "Action": "run"}
+{"Package": "pkg", "Test": "TestThree", "Action": "output", "Output": "    three_test.go:5: real failure\n"}
+{"Package": "pkg", "Test": "TestThree", "Action": "fail"}
+{"Package": "pkg", "Action": "fail"}
+`),
+	})
+	assert.NilError(t, err)
+	exec.SetQuarantine(func(tc TestCase) bool {
+		return tc.Test == "TestOne" || tc.Test == "TestTwo/sub"
+	})
+
+	assert.DeepEqual(t, testNames(exec.Failed()), []string{"TestThree"})
+	assert.DeepEqual(t, testNames(exec.Quarantined()), []string{"TestOne", "TestTwo/sub", "TestTwo"})
+
+	buf := new(bytes.Buffer)
+	PrintSummary(buf, exec, SummarizeAll)
+	expected := `
+=== Failed
+=== FAIL: pkg TestThree (0.00s)
+    three_test.go:5: real failure
+
+=== Quarantined
+=== QUARANTINED: pkg TestOne (0.00s)
+    one_test.go:10: flaky failure
+
+=== QUARANTINED: pkg TestTwo/sub (0.00s)
+
+=== QUARANTINED: pkg TestTwo (0.00s)
+
+DONE 4 tests, 1 failure, 3 quarantined in 0.000s
+`
+	assert.Equal(t, buf.String(), expected)
+}
+
+func testNames(tcs []TestCase) []string {
+	var names []string
+	for _, tc := range tcs {
+		names = append(names, tc.Test.Name())
+	}
+	return names
+}
+
+func TestPrintSummary_WithCoverage(t *testing.T) {
+	_, reset := patchClock()
+	defer reset()
+
+	exec, err := ScanTestOutput(ScanConfig{
+		Stdout: bytes.NewReader(golden.Get(t, "go-test-json-with-cover.out")),
+	})
+	assert.NilError(t, err)
+	exec.SetTotalCoverage(42.5)
+
+	buf := new(bytes.Buffer)
+	PrintSummary(buf, exec, SummarizeCoverage)
+	golden.Assert(t, buf.String(), "summary-with-coverage")
+}
+
+func TestPrintSummary_WithBenchmarks(t *testing.T) {
+	_, reset := patchClock()
+	defer reset()
+
+	exec, err := ScanTestOutput(ScanConfig{
+		Stdout: bytes.NewReader(golden.Get(t, "go-test-json-with-bench.out")),
+	})
+	assert.NilError(t, err)
+
+	buf := new(bytes.Buffer)
+	PrintSummary(buf, exec, SummarizeBenchmarks)
+	golden.Assert(t, buf.String(), "summary-with-benchmarks")
+}
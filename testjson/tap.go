@@ -0,0 +1,164 @@
+package testjson
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const tapVersion = "TAP version 13\n"
+
+// WriteTAP writes a TAP version 13 report of the execution to out. Each package
+// is reported as a subtest, with a test point for every test case in the
+// package. See https://testanything.org/tap-version-13-specification.html
+func WriteTAP(out io.Writer, exec *Execution) error {
+	buf := new(strings.Builder)
+	buf.WriteString(tapVersion)
+	pkgs := exec.Packages()
+	fmt.Fprintf(buf, "1..%d\n", len(pkgs))
+	for i, name := range pkgs {
+		pkg := exec.Package(name)
+		writeTAPPackage(buf, i+1, name, pkg, pkg.Result(), sortedByID(pkg.TestCases()))
+	}
+	_, err := io.WriteString(out, buf.String())
+	return err
+}
+
+// tapFormatter prints a TAP subtest for each package when the package ends.
+// The plan is printed by Close, because the number of packages is not known
+// until all the events have been received.
+type tapFormatter struct {
+	out   io.Writer
+	count int
+}
+
+func newTAPFormatter(out io.Writer) EventFormatter {
+	return &tapFormatter{out: out}
+}
+
+func (f *tapFormatter) Format(event TestEvent, exec *Execution) error {
+	if !event.PackageEvent() {
+		return nil
+	}
+	switch event.Action {
+	case ActionPass, ActionFail, ActionSkip:
+	default:
+		return nil
+	}
+
+	buf := new(strings.Builder)
+	if f.count == 0 {
+		buf.WriteString(tapVersion)
+	}
+	f.count++
+
+	pkg := exec.Package(event.Package)
+	var tcs []TestCase
+	for _, tc := range sortedByID(pkg.TestCases()) {
+		if tc.RunID == event.RunID {
+			tcs = append(tcs, tc)
+		}
+	}
+	name := event.Package + formatRunID(event.RunID)
+	writeTAPPackage(buf, f.count, name, pkg, event.Action, tcs)
+	_, err := io.WriteString(f.out, buf.String())
+	return err
+}
+
+// Close prints the TAP plan.
+func (f *tapFormatter) Close() error {
+	if f.count == 0 {
+		_, err := io.WriteString(f.out, tapVersion+"1..0\n")
+		return err
+	}
+	_, err := fmt.Fprintf(f.out, "1..%d\n", f.count)
+	return err
+}
+
+func writeTAPPackage(buf *strings.Builder, n int, name string, pkg *Package, result Action, tcs []TestCase) {
+	const indent = "    "
+	fmt.Fprintf(buf, "# Subtest: %s\n", name)
+
+	testMainFailed := result == ActionFail && pkg.TestMainFailed()
+	plan := len(tcs)
+	if testMainFailed {
+		plan++
+	}
+	fmt.Fprintf(buf, indent+"1..%d\n", plan)
+
+	for i, tc := range tcs {
+		switch testCaseResult(pkg, tc) {
+		case "FAIL":
+			fmt.Fprintf(buf, indent+"not ok %d - %s\n", i+1, tapEscape(tc.Test.Name()))
+			writeTAPDiagnostic(buf, indent+"  ", tc, pkg.OutputLines(tc))
+		case "SKIP":
+			fmt.Fprintf(buf, indent+"ok %d - %s # SKIP%s\n",
+				i+1, tapEscape(tc.Test.Name()), tapSkipMessage(pkg.OutputLines(tc)))
+		default:
+			fmt.Fprintf(buf, indent+"ok %d - %s\n", i+1, tapEscape(tc.Test.Name()))
+		}
+	}
+	if testMainFailed {
+		fmt.Fprintf(buf, indent+"not ok %d - TestMain\n", plan)
+		writeTAPDiagnostic(buf, indent+"  ", TestCase{}, pkg.OutputLines(TestCase{}))
+	}
+
+	switch result {
+	case ActionFail:
+		fmt.Fprintf(buf, "not ok %d - %s\n", n, tapEscape(name))
+	case ActionSkip:
+		fmt.Fprintf(buf, "ok %d - %s # SKIP no tests\n", n, tapEscape(name))
+	default:
+		fmt.Fprintf(buf, "ok %d - %s\n", n, tapEscape(name))
+	}
+}
+
+// tapEscape escapes the characters which have a special meaning in the
+// description of a test point. A '#' starts a directive, and a backslash
+// escapes the next character.
+func tapEscape(description string) string {
+	return tapEscaper.Replace(description)
+}
+
+var tapEscaper = strings.NewReplacer(`\`, `\\`, "#", `\#`)
+
+// writeTAPDiagnostic writes a YAML block with the elapsed time and the output
+// of a failed test.
+func writeTAPDiagnostic(buf *strings.Builder, indent string, tc TestCase, lines []string) {
+	buf.WriteString(indent + "---\n")
+	if tc.Test != "" && tc.Elapsed != neverFinished {
+		fmt.Fprintf(buf, indent+"duration_ms: %d\n", tc.Elapsed/time.Millisecond)
+	}
+	var output []string
+	for _, line := range lines {
+		if isFramingLine(line) {
+			continue
+		}
+		output = append(output, strings.TrimSuffix(line, "\n"))
+	}
+	if len(output) > 0 {
+		buf.WriteString(indent + "output: |\n")
+		for _, line := range output {
+			buf.WriteString(indent + "  " + line + "\n")
+		}
+	}
+	buf.WriteString(indent + "...\n")
+}
+
+// tapSkipMessage returns the reason a test was skipped from the test output.
+func tapSkipMessage(lines []string) string {
+	var msg []string
+	for _, line := range lines {
+		if isFramingLine(line) || isTestResultLine(line) {
+			continue
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			msg = append(msg, line)
+		}
+	}
+	if len(msg) == 0 {
+		return ""
+	}
+	return " " + strings.Join(msg, " ")
+}
@@ -0,0 +1,203 @@
+package testjson
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// teamcityFormatter prints TeamCity service messages for each event. See
+// https://www.jetbrains.com/help/teamcity/service-messages.html
+//
+// Every test is reported in its own flow, with the flow of the package as the
+// parent, so that the output of parallel tests is attributed to the correct test.
+// TeamCity has no message for a paused test, so a parallel test remains started
+// in its flow while it is paused, and the duration is the elapsed time reported
+// by go test.
+type teamcityFormatter struct {
+	out     io.Writer
+	started map[string]bool
+}
+
+func newTeamCityFormatter(out io.Writer) EventFormatter {
+	return &teamcityFormatter{out: out, started: make(map[string]bool)}
+}
+
+func (f *teamcityFormatter) Format(event TestEvent, exec *Execution) error {
+	buf := new(strings.Builder)
+	pkgFlow := teamcityFlowID(event.Package, "", event.RunID)
+	if !f.started[pkgFlow] {
+		f.started[pkgFlow] = true
+		writeTeamCityMessage(buf, "testSuiteStarted",
+			"name", event.Package,
+			"flowId", pkgFlow)
+	}
+
+	pkg := exec.Package(event.Package)
+	switch {
+	case event.PackageEvent():
+		switch event.Action {
+		case ActionPass, ActionFail, ActionSkip:
+			if pkg.TestMainFailed() {
+				f.writeTestMainFailed(buf, event, pkg)
+			}
+			writeTeamCityMessage(buf, "testSuiteFinished",
+				"name", event.Package,
+				"flowId", pkgFlow)
+			delete(f.started, pkgFlow)
+		}
+	default:
+		f.writeTestEvent(buf, event, pkg, pkgFlow)
+	}
+
+	_, err := io.WriteString(f.out, buf.String())
+	return err
+}
+
+func (f *teamcityFormatter) writeTestEvent(buf *strings.Builder, event TestEvent, pkg *Package, pkgFlow string) {
+	flowID := teamcityFlowID(event.Package, event.Test, event.RunID)
+	switch event.Action {
+	case ActionRun:
+		writeTeamCityMessage(buf, "flowStarted",
+			"flowId", flowID,
+			"parent", pkgFlow)
+		writeTeamCityMessage(buf, "testStarted",
+			"name", event.Test,
+			"captureStandardOutput", "false",
+			"flowId", flowID)
+
+	case ActionOutput:
+		if isFramingLine(event.Output) || isTestResultLine(event.Output) {
+			return
+		}
+		writeTeamCityMessage(buf, "testStdOut",
+			"name", event.Test,
+			"out", strings.TrimSuffix(event.Output, "\n"),
+			"flowId", flowID)
+
+	case ActionFail:
+		tc := pkg.LastFailedByName(event.Test)
+		writeTeamCityMessage(buf, "testFailed",
+			"name", event.Test,
+			"message", "",
+			"details", teamcityDetails(pkg.OutputLines(tc)),
+			"flowId", flowID)
+		writeTeamCityTestFinished(buf, event, flowID)
+
+	case ActionSkip:
+		tc := lastByName(pkg.Skipped, event.Test)
+		writeTeamCityMessage(buf, "testIgnored",
+			"name", event.Test,
+			"message", teamcityDetails(pkg.OutputLines(tc)),
+			"flowId", flowID)
+		writeTeamCityTestFinished(buf, event, flowID)
+
+	case ActionPass:
+		writeTeamCityTestFinished(buf, event, flowID)
+
+	case ActionPause, ActionCont:
+		// The test remains started in its own flow. See teamcityFormatter.
+	}
+}
+
+// writeTestMainFailed reports a package which failed without any test failures
+// as a failed test, so that the failure is visible in TeamCity.
+func (f *teamcityFormatter) writeTestMainFailed(buf *strings.Builder, event TestEvent, pkg *Package) {
+	flowID := teamcityFlowID(event.Package, "TestMain", event.RunID)
+	writeTeamCityMessage(buf, "testStarted",
+		"name", "TestMain",
+		"flowId", flowID)
+	writeTeamCityMessage(buf, "testFailed",
+		"name", "TestMain",
+		"message", "package failed without any test failures",
+		"details", teamcityDetails(pkg.OutputLines(TestCase{})),
+		"flowId", flowID)
+	writeTeamCityMessage(buf, "testFinished",
+		"name", "TestMain",
+		"flowId", flowID)
+}
+
+func writeTeamCityTestFinished(buf *strings.Builder, event TestEvent, flowID string) {
+	writeTeamCityMessage(buf, "testFinished",
+		"name", event.Test,
+		"duration", fmt.Sprintf("%d", elapsedDuration(event.Elapsed)/time.Millisecond),
+		"flowId", flowID)
+	writeTeamCityMessage(buf, "flowFinished",
+		"flowId", flowID)
+}
+
+func teamcityFlowID(pkg, test string, runID int) string {
+	flowID := pkg
+	if test != "" {
+		flowID += "." + test
+	}
+	if runID > 0 {
+		flowID += fmt.Sprintf("#%d", runID)
+	}
+	return flowID
+}
+
+func lastByName(tcs []TestCase, name string) TestCase {
+	for i := len(tcs) - 1; i >= 0; i-- {
+		if tcs[i].Test.Name() == name {
+			return tcs[i]
+		}
+	}
+	return TestCase{}
+}
+
+// isTestResultLine returns true if the line is the --- PASS, --- FAIL or
+// --- SKIP line printed by go test at the end of a test.
+func isTestResultLine(line string) bool {
+	line = strings.TrimLeft(line, " ")
+	return strings.HasPrefix(line, "--- PASS: ") ||
+		strings.HasPrefix(line, "--- FAIL: ") ||
+		strings.HasPrefix(line, "--- SKIP: ")
+}
+
+// teamcityDetails returns the output lines with the framing and result lines
+// removed.
+func teamcityDetails(lines []string) string {
+	var result strings.Builder
+	for _, line := range lines {
+		if isFramingLine(line) || isTestResultLine(line) {
+			continue
+		}
+		result.WriteString(line)
+	}
+	return strings.TrimSuffix(result.String(), "\n")
+}
+
+// writeTeamCityMessage writes a service message with the attributes from
+// pairs of name and value.
+func writeTeamCityMessage(buf *strings.Builder, name string, attrs ...string) {
+	buf.WriteString("##teamcity[" + name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		buf.WriteString(" " + attrs[i] + "='" + teamcityEscape(attrs[i+1]) + "'")
+	}
+	buf.WriteString("]\n")
+}
+
+// teamcityEscape a value of a service message attribute.
+func teamcityEscape(s string) string {
+	var buf strings.Builder
+	for _, r := range s {
+		switch r {
+		case '|', '\'', '[', ']':
+			buf.WriteRune('|')
+			buf.WriteRune(r)
+		case '\n':
+			buf.WriteString("|n")
+		case '\r':
+			buf.WriteString("|r")
+		default:
+			if r > 0x7f {
+				fmt.Fprintf(&buf, "|0x%04x", r)
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}